@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"go-auth-service/config"
+)
+
+const configUsage = `usage: api config <command>
+
+commands:
+  check         print the effective configuration, with secrets redacted,
+                and exit non-zero if it is invalid`
+
+// runConfig implements the "config" subcommand. It runs before logging is
+// configured so that it can report an invalid LOG_LEVEL or LOG_FORMAT too.
+func runConfig(args []string) {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, s := range cfg.Settings() {
+		fmt.Printf("%s=%s\n", s.Key, s.Value)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\ninvalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "\nconfiguration is valid")
+}
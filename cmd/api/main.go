@@ -1,43 +1,412 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"go-auth-service/config"
 	"go-auth-service/internal/delivery/http"
 	"go-auth-service/internal/delivery/http/middleware"
+	"go-auth-service/internal/domain"
 	"go-auth-service/internal/infrastructure"
 	"go-auth-service/internal/repository"
 	"go-auth-service/internal/service"
 	"go-auth-service/internal/usecase"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfig(os.Args[2:])
+		return
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Printf("Warning: Failed to load config file: %v. Using environment variables.", err)
+		fatal("failed to load configuration", "error", err)
 	}
 
-	db := infrastructure.NewDatabase(cfg)
-	redisClient := infrastructure.NewRedisClient(cfg)
+	logger, err := infrastructure.NewLogger(cfg, os.Stdout)
+	if err != nil {
+		fatal("failed to configure logging", "error", err)
+	}
+	// Also routes the standard log package, used by dependencies, to slog.
+	slog.SetDefault(logger)
 
-	userRepo := repository.NewUserRepository(db)
-	tokenService := service.NewTokenService(cfg)
-	passwordService := service.NewPasswordService()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
 
-	authUsecase := usecase.NewAuthUsecase(userRepo, tokenService, passwordService, redisClient)
-	authMiddleware := middleware.NewAuthMiddleware(tokenService, redisClient)
+	shutdownTracing, err := infrastructure.NewTracing(context.Background(), cfg)
+	if err != nil {
+		fatal("failed to configure tracing", "error", err)
+	}
 
-	app := fiber.New()
-	app.Use(logger.New())
+	db, err := infrastructure.NewDatabase(cfg)
+	if err != nil {
+		fatal("failed to open database", "error", err)
+	}
+	ensureSchema(cfg, db)
+	if err := repository.TraceDB(db); err != nil {
+		fatal("failed to instrument database", "error", err)
+	}
+	redisClient, err := infrastructure.NewRedisClient(cfg)
+	if err != nil {
+		fatal("failed to configure Redis", "error", err)
+	}
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		fatal("failed to instrument Redis", "error", err)
+	}
+
+	var metrics *service.PrometheusMetrics
+	if cfg.MetricsEnabled {
+		metrics = service.NewPrometheusMetrics()
+		if err := repository.InstrumentDB(db, metrics); err != nil {
+			fatal("failed to instrument database", "error", err)
+		}
+		redisClient.AddHook(repository.NewRedisMetricsHook(metrics))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	bg := &workers{ctx: ctx}
+
+	secretCipher, err := service.NewSecretCipher(cfg)
+	if err != nil {
+		fatal("failed to configure encryption", "error", err)
+	}
+
+	outboxRepo := repository.NewOutboxRepository(db)
+	userRepo := newUserRepository(cfg, db, outboxRepo)
+	keyRing := service.NewKeyRing(cfg, repository.NewSigningKeyRepository(db, secretCipher))
+	if err := keyRing.Reload(context.Background()); err != nil {
+		fatal("failed to configure token signing", "error", err)
+	}
+	bg.Go(func(ctx context.Context) { keyRing.Run(ctx, cfg.SigningKeyReloadInterval) })
+	tokenService := service.NewTokenService(cfg, keyRing)
+	passwordService, err := service.NewPasswordService(cfg)
+	if err != nil {
+		fatal("failed to configure password hashing", "error", err)
+	}
+
+	auditRepo := repository.NewAuditRepository(db, service.NewHMACSigner(cfg.AuditSigningKey), cfg.AuditCheckpointInterval)
+	auditLogger := service.NewAuditLogger(auditRepo)
+	bg.Go(func(ctx context.Context) { auditLogger.Run(ctx, cfg.AuditHeadSignInterval) })
+
+	webhookRepo := repository.NewWebhookRepository(db, secretCipher)
+	webhookService := service.NewWebhookService(cfg, webhookRepo)
+	if cfg.WebhookWorkerEnabled {
+		bg.Go(webhookService.Run)
+	}
+
+	if cfg.OutboxRelayEnabled {
+		bg.Go(newOutboxRelay(cfg, outboxRepo, webhookService, redisClient).Run)
+	}
+
+	var breachChecker *service.BreachedPasswordChecker
+	var extraRules []domain.PasswordRule
+	if cfg.PasswordBreachCorpus != "" {
+		breachChecker, err = service.NewBreachedPasswordChecker(cfg.PasswordBreachCorpus, cfg.PasswordBreachMinCount)
+		if err != nil {
+			fatal("failed to configure breached password check", "error", err)
+		}
+		extraRules = append(extraRules, breachChecker)
+	}
+
+	passwordPolicy, err := service.NewPasswordPolicy(cfg, extraRules...)
+	if err != nil {
+		fatal("failed to configure password policy", "error", err)
+	}
+
+	usecaseOpts := []usecase.Option{
+		usecase.WithAuditLogger(auditLogger),
+		usecase.WithEventPublisher(outboxRepo),
+		usecase.WithPasswordPolicy(passwordPolicy),
+	}
+	if breachChecker != nil && cfg.PasswordBreachCheckOnLogin {
+		usecaseOpts = append(usecaseOpts, usecase.WithLoginBreachCheck(breachChecker))
+	}
+	if cfg.PasswordHistoryDepth > 0 {
+		historyRepo := repository.NewPasswordHistoryRepository(db, cfg.PasswordHistoryDepth, cfg.PasswordHistoryRetention)
+		usecaseOpts = append(usecaseOpts, usecase.WithPasswordHistory(historyRepo))
+	}
+	revocations := newRevocationStore(cfg, db, redisClient, bg)
+
+	var sessionRepo domain.SessionRepository
+	if cfg.SessionsEnabled {
+		sessionRepo = service.NewRevokingSessionRepository(repository.NewSessionRepository(db), tokenService, revocations, cfg.JWTAccessExpiry)
+		usecaseOpts = append(usecaseOpts, usecase.WithSessions(sessionRepo))
+		if metrics != nil {
+			bg.Go(func(ctx context.Context) {
+				metrics.TrackActiveSessions(ctx, sessionRepo.CountActive, cfg.MetricsSessionsInterval)
+			})
+		}
+	}
+	if cfg.LoginThreatEnabled {
+		detector, err := service.NewLoginThreatDetector(cfg, redisClient)
+		if err != nil {
+			fatal("failed to configure login threat detector", "error", err)
+		}
+		usecaseOpts = append(usecaseOpts, usecase.WithLoginThreatDetector(detector))
+		if cfg.LoginThreatChallengeVerifyURL != "" {
+			usecaseOpts = append(usecaseOpts, usecase.WithChallengeVerifier(service.NewSiteVerifier(cfg)))
+		}
+	}
+
+	// A nil *PrometheusMetrics must not become a non-nil domain.Metrics.
+	var authMetrics domain.Metrics
+	if metrics != nil {
+		authMetrics = metrics
+		usecaseOpts = append(usecaseOpts, usecase.WithMetrics(metrics))
+	}
+
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenService, passwordService, revocations, usecaseOpts...)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo)
+	authMiddleware := middleware.NewAuthMiddleware(tokenService, revocations, userRepo, authMetrics)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: http.ErrorHandler,
+		BodyLimit:    cfg.ServerBodyLimit,
+		// The client address is taken from ProxyHeader only on requests from
+		// a trusted proxy, and otherwise is the peer address.
+		ProxyHeader:             cfg.ServerProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          splitList(cfg.ServerTrustedProxies),
+		EnableIPValidation:      true,
+	})
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestID())
+	app.Use(middleware.RequestLogger())
 
 	http.RegisterUserRoutes(app, authUsecase, authMiddleware)
+	http.RegisterAdminRoutes(app, auditUsecase, auditLogger, webhookUsecase, revocations, authMiddleware)
+	http.RegisterHealthRoutes(app, readinessChecks(cfg, db, redisClient, keyRing))
+
+	listenErr := make(chan error, 2)
+	go func() {
+		slog.Info("server starting", "port", cfg.ServerPort)
+		listenErr <- app.Listen(":" + strconv.Itoa(cfg.ServerPort))
+	}()
+
+	// Metrics get their own listener, so the public port never serves them.
+	var metricsApp *fiber.App
+	if metrics != nil {
+		metricsApp = fiber.New(fiber.Config{DisableStartupMessage: true})
+		http.RegisterMetricsRoutes(metricsApp, metrics.Handler())
+		go func() {
+			slog.Info("metrics server starting", "port", cfg.MetricsPort)
+			listenErr <- metricsApp.Listen(":" + strconv.Itoa(cfg.MetricsPort))
+		}()
+	}
+
+	select {
+	case err := <-listenErr:
+		fatal("server failed to start", "error", err)
+	case <-ctx.Done():
+	}
+	stop()
 
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	if err := app.Listen(":" + cfg.ServerPort); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// Stop accepting connections and let in-flight requests, e.g. logins
+	// waiting on the password hash, finish before the pools they use close.
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+	if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+		slog.Warn("server shutdown failed", "error", err)
 	}
+	if metricsApp != nil {
+		if err := metricsApp.Shutdown(); err != nil {
+			slog.Warn("metrics server shutdown failed", "error", err)
+		}
+	}
+	bg.Wait()
+
+	// Sign the chain head after the last request could have appended to it.
+	signCtx, cancelSign := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := auditLogger.SignHead(signCtx); err != nil {
+		slog.Warn("failed to sign audit chain head", "error", err)
+	}
+	cancelSign()
+
+	if err := redisClient.Close(); err != nil {
+		slog.Warn("failed to close Redis client", "error", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Warn("failed to close database", "error", err)
+		}
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// fatal logs an error and exits; the slog counterpart of log.Fatal.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// workers runs background loops until shutdown, so main can wait for them
+// before closing the connection pools they use.
+type workers struct {
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+func (w *workers) Go(run func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(w.ctx)
+	}()
+}
+
+func (w *workers) Wait() {
+	w.wg.Wait()
+}
+
+// readinessChecks covers the dependencies requests need. Redis is checked
+// only if a configured feature uses it.
+func readinessChecks(cfg config.Config, db *gorm.DB, redisClient redis.UniversalClient, keyRing *service.KeyRing) []domain.ReadinessCheck {
+	checks := []domain.ReadinessCheck{
+		{Name: "database", Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		{Name: "signing_keys", Check: func(ctx context.Context) error {
+			return keyRing.Ready()
+		}},
+	}
+
+	if usesRedis(cfg) {
+		checks = append(checks, domain.ReadinessCheck{Name: "redis", Check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}})
+	}
+
+	// SQLite schemas are created from the models, not migrated.
+	if db.Dialector.Name() == "postgres" {
+		migrator := newMigrator(db)
+		checks = append(checks, domain.ReadinessCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return fmt.Errorf("%d pending migrations", pending)
+			}
+			return nil
+		}})
+	}
+
+	return checks
+}
+
+func usesRedis(cfg config.Config) bool {
+	if cfg.RevocationStore == "redis" || cfg.LoginThreatEnabled {
+		return true
+	}
+	if domain.RevocationFailureMode(cfg.RevocationFailureMode) == domain.RevocationFailover && cfg.RevocationFallbackStore == "redis" {
+		return true
+	}
+	return cfg.OutboxRelayEnabled && strings.Contains(cfg.OutboxSinks, "redis_stream")
+}
+
+// newRevocationStore builds the configured store, wrapped with the failure
+// mode that applies while it is unreachable.
+func newRevocationStore(cfg config.Config, db *gorm.DB, redisClient redis.UniversalClient, bg *workers) *service.ResilientRevocationStore {
+	primary := newRevocationBackend(cfg.RevocationStore, db, redisClient, cfg.RedisKeyPrefix)
+	if cfg.RevocationStore == "redis" && cfg.RevocationCacheEnabled {
+		cache := service.NewRevocationCache(primary, redisClient, cfg.RedisKeyPrefix, cfg.RedisKeyPrefix+cfg.RevocationCacheChannel, cfg.RevocationCacheMaxEntries)
+		bg.Go(cache.Run)
+		primary = cache
+	}
+
+	var fallback domain.RevocationStore
+	mode := domain.RevocationFailureMode(cfg.RevocationFailureMode)
+	if mode == domain.RevocationFailover {
+		fallback = newRevocationBackend(cfg.RevocationFallbackStore, db, redisClient, cfg.RedisKeyPrefix)
+	}
+
+	store, err := service.NewResilientRevocationStore(primary, fallback, mode)
+	if err != nil {
+		fatal("failed to configure revocation store", "error", err)
+	}
+	bg.Go(store.Run)
+	return store
+}
+
+// newUserRepository keeps users in the database unless USER_STORE=memory.
+// The in-memory store still publishes its events to the outbox.
+func newUserRepository(cfg config.Config, db *gorm.DB, outbox domain.EventPublisher) domain.UserRepository {
+	if cfg.UserStore == "memory" {
+		slog.Warn("in-memory user store is not shared between instances and is lost on restart")
+		return repository.NewMemoryUserRepository(outbox)
+	}
+	return repository.NewUserRepository(db)
+}
+
+func newRevocationBackend(name string, db *gorm.DB, redisClient redis.UniversalClient, keyPrefix string) domain.RevocationStore {
+	switch name {
+	case "redis":
+		return repository.NewRedisRevocationStore(redisClient, keyPrefix)
+	case "postgres":
+		return repository.NewPostgresRevocationStore(db)
+	case "memory":
+		slog.Warn("in-memory revocation store is not shared between instances and is lost on restart")
+		return repository.NewMemoryRevocationStore()
+	default:
+		fatal("unknown revocation store", "store", name)
+		return nil
+	}
+}
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func newOutboxRelay(cfg config.Config, outboxRepo domain.OutboxRepository, webhookService *service.WebhookService, redisClient redis.UniversalClient) *service.OutboxRelay {
+	var sinks []domain.EventSink
+	for _, name := range strings.Split(cfg.OutboxSinks, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "webhook":
+			sinks = append(sinks, webhookService)
+		case "redis_stream":
+			sinks = append(sinks, service.NewRedisStreamSink(redisClient, cfg.RedisKeyPrefix+cfg.OutboxRedisStream, cfg.OutboxRedisStreamLen))
+		case "stdout":
+			sinks = append(sinks, service.NewStdoutSink(os.Stdout))
+		default:
+			fatal("unknown outbox sink", "sink", name)
+		}
+	}
+
+	return service.NewOutboxRelay(cfg, outboxRepo, sinks)
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/migrations"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: api migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [N]      roll back the last N migrations (default 1)
+  status        list migrations and when they were applied
+  to VERSION    migrate up or down to VERSION (0 rolls back everything)`
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(cfg config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if cfg.DBDriver != "postgres" {
+		fatal("the SQL migrations target Postgres; other databases are created from the models on startup", "driver", cfg.DBDriver)
+	}
+
+	db, err := infrastructure.NewDatabase(cfg)
+	if err != nil {
+		fatal("failed to open database", "error", err)
+	}
+	migrator := newMigrator(db)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fatal("invalid number of migrations to roll back", "steps", args[1])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "status":
+		err = printMigrationStatus(ctx, migrator)
+	case "to":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			fatal("invalid migration version", "version", args[1])
+		}
+		err = migrator.To(ctx, uint(version))
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fatal("migration failed", "error", err)
+	}
+}
+
+func printMigrationStatus(ctx context.Context, migrator *infrastructure.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		state := "pending"
+		if s.AppliedAt != nil {
+			state = "applied " + s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		if s.Missing {
+			state += " (script missing)"
+		}
+		fmt.Printf("%06d  %-40s %s\n", s.Version, s.Name, state)
+	}
+	return nil
+}
+
+func newMigrator(db *gorm.DB) *infrastructure.Migrator {
+	migrator, err := infrastructure.NewMigrator(db, migrations.FS)
+	if err != nil {
+		fatal("failed to load migrations", "error", err)
+	}
+	return migrator
+}
+
+// ensureSchema applies pending migrations when MIGRATE_ON_START is set, and
+// otherwise refuses to start against an outdated schema.
+func ensureSchema(cfg config.Config, db *gorm.DB) {
+	if db.Dialector.Name() != "postgres" {
+		return
+	}
+
+	migrator := newMigrator(db)
+	ctx := context.Background()
+
+	if cfg.MigrateOnStart {
+		if err := migrator.Up(ctx); err != nil {
+			fatal("failed to migrate database", "error", err)
+		}
+		return
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		fatal("failed to check database migrations", "error", err)
+	}
+	if pending > 0 {
+		fatal("database has pending migrations; run \"api migrate up\" or set MIGRATE_ON_START=true", "pending", pending)
+	}
+}
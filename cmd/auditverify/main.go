@@ -0,0 +1,58 @@
+// Command auditverify walks the audit_events hash chain and its signed
+// checkpoints and reports the first broken link. It exits with status 1 if
+// the chain has been tampered with.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+)
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print the result as JSON")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := infrastructure.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	signer := service.NewHMACSigner(cfg.AuditSigningKey)
+	auditRepo := repository.NewAuditRepository(db, signer, cfg.AuditCheckpointInterval)
+
+	result, err := service.NewAuditVerifier(auditRepo, signer).Verify(context.Background())
+	if err != nil {
+		log.Fatalf("Audit verification failed: %v", err)
+	}
+
+	if *jsonOutput {
+		json.NewEncoder(os.Stdout).Encode(result)
+	} else if result.Break == nil {
+		fmt.Printf("OK: %d events and %d checkpoints verified, chain head at seq %d\n",
+			result.EventsVerified, result.CheckpointsVerified, result.Head)
+		if result.UnsignedEvents > 0 {
+			fmt.Printf("%d events after the signed head at seq %d are not covered by a checkpoint yet\n",
+				result.UnsignedEvents, result.SignedHead)
+		}
+	} else {
+		fmt.Printf("BROKEN at seq %d (event id %d): %s\n", result.Break.Seq, result.Break.EventID, result.Break.Reason)
+		fmt.Printf("%d events verified before the break\n", result.EventsVerified)
+	}
+
+	if result.Break != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"gorm.io/gorm"
+)
+
+type keyInfo struct {
+	domain.SigningKey
+	Current bool `json:"current"`
+}
+
+func (a *app) keyList(args []string) error {
+	if _, err := parse(flag.NewFlagSet("key list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+
+	keys, err := a.keys.List(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	infos := make([]keyInfo, len(keys))
+	lines := []string{}
+	for i, key := range keys {
+		kid, _ := a.keyRing.SigningKey(key.Purpose)
+		infos[i] = keyInfo{SigningKey: key, Current: kid == key.ID}
+
+		marker := ""
+		if infos[i].Current {
+			marker = "  (current)"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %-7s  created %s%s", key.ID, key.Purpose, key.CreatedAt.UTC().Format(time.RFC3339), marker))
+	}
+	for _, purpose := range []string{domain.KeyPurposeAccess, domain.KeyPurposeRefresh} {
+		if kid, _ := a.keyRing.SigningKey(purpose); kid == "" {
+			lines = append(lines, fmt.Sprintf("%s tokens are signed with the configured secret", purpose))
+		}
+	}
+	a.print(infos, lines...)
+	return nil
+}
+
+// keyRotate adds a new key, which every instance starts signing with after
+// its next key reload. Older keys keep verifying tokens until retired.
+func (a *app) keyRotate(args []string) error {
+	fs := flag.NewFlagSet("key rotate", flag.ContinueOnError)
+	purpose := fs.String("purpose", "", "rotate only the access or refresh key")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	purposes := []string{domain.KeyPurposeAccess, domain.KeyPurposeRefresh}
+	if *purpose != "" {
+		purposes = []string{*purpose}
+	}
+
+	var created []domain.SigningKey
+	var lines []string
+	for _, p := range purposes {
+		key, err := service.NewSigningKey(p)
+		if err != nil {
+			return err
+		}
+		if err := a.keys.Create(a.ctx, key); err != nil {
+			return err
+		}
+		a.audit(domain.AuditActionKeyRotate, key.ID)
+
+		created = append(created, *key)
+		lines = append(lines, fmt.Sprintf("Created %s key %s", key.Purpose, key.ID))
+	}
+	lines = append(lines, fmt.Sprintf("Instances pick up new keys within SIGNING_KEY_RELOAD_INTERVAL (%s)", a.cfg.SigningKeyReloadInterval))
+	a.print(created, lines...)
+	return nil
+}
+
+// keyRetire deletes a key. Tokens it signed stop verifying immediately on
+// instances that reload their keys, so retire a key only once the tokens it
+// signed have expired.
+func (a *app) keyRetire(args []string) error {
+	rest, err := parse(flag.NewFlagSet("key retire", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	kid := rest[0]
+	if current, _ := a.keyRing.SigningKey(domain.KeyPurposeAccess); current == kid {
+		return errors.New("cannot retire the current access key, rotate first")
+	}
+	if current, _ := a.keyRing.SigningKey(domain.KeyPurposeRefresh); current == kid {
+		return errors.New("cannot retire the current refresh key, rotate first")
+	}
+
+	if err := a.keys.Delete(a.ctx, kid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("key %s not found", kid)
+		}
+		return err
+	}
+	a.audit(domain.AuditActionKeyRetire, kid)
+
+	a.print(map[string]any{"retired": kid}, "Retired key "+kid)
+	return nil
+}
+
+func (a *app) audit(action, reason string) {
+	err := a.auditLog.Log(a.ctx, &domain.AuditEvent{
+		Action:  action,
+		Outcome: domain.AuditOutcomeSuccess,
+		Reason:  reason,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to write audit event %s: %v", action, err)
+	}
+}
@@ -0,0 +1,306 @@
+// Command authctl performs operator actions against the auth service's
+// database: managing users, their sessions and the token signing keys. Every
+// change is written to the audit log.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+	"go-auth-service/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: authctl [-json] <command> [flags] [args]
+
+users (USER is a user ID or email address):
+  user create -email EMAIL [-name NAME] [-role ROLE] [-password-stdin]
+  user show USER
+  user set-password [-password-stdin] [-must-change] USER
+  user reset-password USER
+  user disable USER
+  user enable USER
+  user set-role USER ROLE
+
+sessions:
+  session list USER
+  session revoke SESSION_ID
+  session revoke-all USER
+
+signing keys:
+  key list
+  key rotate [-purpose access|refresh]
+  key retire KID
+
+tokens:
+  token inspect TOKEN
+
+encrypted columns:
+  secrets reencrypt
+
+Without -password-stdin a random password is generated and printed.`
+
+// generatedPasswordLength is well above any sensible PASSWORD_MIN_LENGTH.
+const generatedPasswordLength = 20
+
+type app struct {
+	ctx        context.Context
+	cfg        config.Config
+	jsonOutput bool
+
+	db       *gorm.DB
+	users    domain.UserRepository
+	admin    domain.UserAdminUsecase
+	keys     domain.SigningKeyRepository
+	keyRing  *service.KeyRing
+	tokens   *service.TokenService
+	cipher   domain.SecretCipher
+	auditLog *service.AuditLogger
+
+	revocations domain.RevocationStore
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	jsonOutput := flag.Bool("json", false, "print results as JSON")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("authctl: %v", err)
+	}
+
+	a, err := newApp(cfg, *jsonOutput)
+	if err != nil {
+		log.Fatalf("authctl: %v", err)
+	}
+
+	err = a.run(args[0], args[1], args[2:])
+	if cache, ok := a.revocations.(*service.RevocationCache); ok && cache.Unannounced() > 0 {
+		log.Printf("authctl: %d revocations could not be announced; running instances accept the revoked tokens until their cache reloads", cache.Unannounced())
+	}
+	// Sign the events this run appended, so truncating them is detected.
+	if signErr := a.auditLog.SignHead(a.ctx); signErr != nil {
+		log.Printf("authctl: failed to sign audit chain head: %v", signErr)
+	}
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			flag.Usage()
+			os.Exit(2)
+		}
+		log.Fatalf("authctl: %v", err)
+	}
+}
+
+func newApp(cfg config.Config, jsonOutput bool) (*app, error) {
+	db, err := infrastructure.NewDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordService, err := service.NewPasswordService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure password hashing: %w", err)
+	}
+
+	var extraRules []domain.PasswordRule
+	if cfg.PasswordBreachCorpus != "" {
+		checker, err := service.NewBreachedPasswordChecker(cfg.PasswordBreachCorpus, cfg.PasswordBreachMinCount)
+		if err != nil {
+			return nil, err
+		}
+		extraRules = append(extraRules, checker)
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg, extraRules...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure password policy: %w", err)
+	}
+
+	auditRepo := repository.NewAuditRepository(db, service.NewHMACSigner(cfg.AuditSigningKey), cfg.AuditCheckpointInterval)
+	auditLogger := service.NewAuditLogger(auditRepo)
+
+	userRepo := repository.NewUserRepository(db)
+	secretCipher, err := service.NewSecretCipher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure encryption: %w", err)
+	}
+	keyRepo := repository.NewSigningKeyRepository(db, secretCipher)
+	keyRing := service.NewKeyRing(cfg, keyRepo)
+	tokens := service.NewTokenService(cfg, keyRing)
+
+	revocations, err := newRevocationStore(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	sessions := service.NewRevokingSessionRepository(repository.NewSessionRepository(db), tokens, revocations, cfg.JWTAccessExpiry)
+
+	// Audit events of operator actions name the operator instead of a client.
+	operator := "unknown"
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	host, _ := os.Hostname()
+	ctx := domain.ContextWithClientInfo(context.Background(), domain.ClientInfo{
+		UserAgent: fmt.Sprintf("authctl (%s@%s)", operator, host),
+	})
+
+	if err := keyRing.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &app{
+		ctx:         ctx,
+		cfg:         cfg,
+		jsonOutput:  jsonOutput,
+		db:          db,
+		users:       userRepo,
+		admin:       usecase.NewUserAdminUsecase(userRepo, passwordService, sessions, passwordPolicy, auditLogger),
+		keys:        keyRepo,
+		keyRing:     keyRing,
+		tokens:      tokens,
+		cipher:      secretCipher,
+		auditLog:    auditLogger,
+		revocations: revocations,
+	}, nil
+}
+
+// newRevocationStore opens the store the API checks, so that the access
+// tokens of sessions ended here are rejected there. With the revocation
+// cache, revocations are announced to the running instances. The in-memory
+// store lives inside the API process and cannot be reached from here.
+func newRevocationStore(cfg config.Config, db *gorm.DB) (domain.RevocationStore, error) {
+	switch cfg.RevocationStore {
+	case "redis":
+		client, err := infrastructure.NewRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		store := repository.NewRedisRevocationStore(client, cfg.RedisKeyPrefix)
+		if !cfg.RevocationCacheEnabled {
+			return store, nil
+		}
+		return service.NewRevocationCache(store, client, cfg.RedisKeyPrefix, cfg.RedisKeyPrefix+cfg.RevocationCacheChannel, cfg.RevocationCacheMaxEntries), nil
+	case "postgres":
+		return repository.NewPostgresRevocationStore(db), nil
+	default:
+		log.Printf("authctl: the %s revocation store is not reachable, access tokens of revoked sessions stay valid until they expire", cfg.RevocationStore)
+		return repository.NewMemoryRevocationStore(), nil
+	}
+}
+
+var errUsage = errors.New("usage")
+
+func (a *app) run(group, command string, args []string) error {
+	switch group + " " + command {
+	case "user create":
+		return a.userCreate(args)
+	case "user show":
+		return a.userShow(args)
+	case "user set-password":
+		return a.userSetPassword(args)
+	case "user reset-password":
+		return a.userResetPassword(args)
+	case "user disable":
+		return a.userSetDisabled(args, true)
+	case "user enable":
+		return a.userSetDisabled(args, false)
+	case "user set-role":
+		return a.userSetRole(args)
+	case "session list":
+		return a.sessionList(args)
+	case "session revoke":
+		return a.sessionRevoke(args)
+	case "session revoke-all":
+		return a.sessionRevokeAll(args)
+	case "key list":
+		return a.keyList(args)
+	case "key rotate":
+		return a.keyRotate(args)
+	case "key retire":
+		return a.keyRetire(args)
+	case "token inspect":
+		return a.tokenInspect(args)
+	case "secrets reencrypt":
+		return a.secretsReencrypt(args)
+	default:
+		return errUsage
+	}
+}
+
+// findUser resolves a numeric ID or an email address.
+func (a *app) findUser(ref string) (*domain.User, error) {
+	var (
+		u   *domain.User
+		err error
+	)
+	if id, parseErr := strconv.ParseUint(ref, 10, 64); parseErr == nil {
+		u, err = a.users.GetByID(a.ctx, uint(id))
+	} else {
+		u, err = a.users.GetByEmail(a.ctx, ref)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("user %s not found", ref)
+	}
+	return u, err
+}
+
+// print writes v as JSON with -json, and the text lines otherwise.
+func (a *app) print(v any, text ...string) {
+	if a.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(v)
+		return
+	}
+	for _, line := range text {
+		fmt.Println(line)
+	}
+}
+
+// password reads a password from the first line of stdin, or generates one.
+func password(fromStdin bool) (string, bool, error) {
+	if !fromStdin {
+		p, err := service.GeneratePassword(generatedPasswordLength)
+		return p, true, err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false, fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), false, nil
+}
+
+// parse parses flags and requires exactly n positional arguments.
+func parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() != n {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
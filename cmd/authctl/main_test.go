@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestApp(t *testing.T) *app {
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("JWT_REFRESH_SECRET", "fedcba9876543210fedcba9876543210")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.DBDriver = "sqlite"
+	cfg.DBPath = filepath.Join(t.TempDir(), "auth.db")
+	cfg.PasswordHashAlgorithm = "bcrypt"
+	cfg.PasswordBcryptCost = 4
+	cfg.RevocationStore = "postgres"
+
+	a, err := newApp(cfg, true)
+	require.NoError(t, err)
+	return a
+}
+
+func TestUserCommands(t *testing.T) {
+	a := newTestApp(t)
+	sessions := repository.NewSessionRepository(a.db)
+
+	require.NoError(t, a.run("user", "create", []string{"-email", "ops@example.com", "-role", domain.RoleAdmin}))
+	u, err := a.findUser("ops@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, u.Role)
+
+	t.Run("Disable", func(t *testing.T) {
+		session := &domain.Session{UserID: u.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, sessions.Create(a.ctx, session, "refresh"))
+
+		require.NoError(t, a.run("user", "disable", []string{"ops@example.com"}))
+
+		found, err := a.findUser("ops@example.com")
+		require.NoError(t, err)
+		assert.NotNil(t, found.DisabledAt)
+		active, err := sessions.IsActive(a.ctx, session.ID)
+		require.NoError(t, err)
+		assert.False(t, active, "disabling revokes the sessions")
+
+		require.NoError(t, a.run("user", "enable", []string{"ops@example.com"}))
+		found, err = a.findUser("ops@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, found.DisabledAt)
+	})
+
+	t.Run("ResetPassword", func(t *testing.T) {
+		require.NoError(t, a.run("user", "reset-password", []string{"ops@example.com"}))
+
+		found, err := a.findUser("ops@example.com")
+		require.NoError(t, err)
+		assert.True(t, found.MustChangePassword)
+		assert.NotEqual(t, u.Password, found.Password)
+	})
+
+	t.Run("SetRole", func(t *testing.T) {
+		require.NoError(t, a.run("user", "set-role", []string{"ops@example.com", domain.RoleUser}))
+		assert.Error(t, a.run("user", "set-role", []string{"ops@example.com", "root"}))
+
+		found, err := a.findUser("ops@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, domain.RoleUser, found.Role)
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		first := &domain.Session{UserID: u.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		second := &domain.Session{UserID: u.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, sessions.Create(a.ctx, first, "first"))
+		require.NoError(t, sessions.Create(a.ctx, second, "second"))
+
+		require.NoError(t, a.run("session", "list", []string{"ops@example.com"}))
+		require.NoError(t, a.run("session", "revoke", []string{first.ID}))
+		assert.EqualError(t, a.run("session", "revoke", []string{first.ID}), "session "+first.ID+" not found")
+		require.NoError(t, a.run("session", "revoke-all", []string{"ops@example.com"}))
+
+		active, err := sessions.IsActive(a.ctx, second.ID)
+		require.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		assert.EqualError(t, a.run("user", "show", []string{"nobody@example.com"}), "user nobody@example.com not found")
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		assert.ErrorIs(t, a.run("user", "frobnicate", nil), errUsage)
+		assert.ErrorIs(t, a.run("user", "show", nil), errUsage)
+	})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/repository"
+)
+
+// secretsReencrypt encrypts values stored before encryption was enabled, and
+// moves encrypted ones to the key provider's current key so older keyring
+// versions can be removed.
+func (a *app) secretsReencrypt(args []string) error {
+	if _, err := parse(flag.NewFlagSet("secrets reencrypt", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+
+	if a.cfg.EncryptionKeyProvider == "none" {
+		return errors.New("ENCRYPTION_KEY_PROVIDER is none, there is no key to encrypt with")
+	}
+
+	count, err := repository.ReencryptSecrets(a.ctx, a.db, a.cipher)
+	if err != nil {
+		return err
+	}
+	a.audit(domain.AuditActionReencrypt, fmt.Sprintf("%d values", count))
+
+	a.print(map[string]any{"reencrypted": count}, fmt.Sprintf("Re-encrypted %d values", count))
+	return nil
+}
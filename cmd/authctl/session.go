@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (a *app) sessionList(args []string) error {
+	rest, err := parse(flag.NewFlagSet("session list", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+
+	sessions, err := a.admin.ListSessions(a.ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{fmt.Sprintf("%d sessions of user %d (%s)", len(sessions), u.ID, u.Email)}
+	for _, s := range sessions {
+		lines = append(lines, fmt.Sprintf("%s  created %s  last used %s  expires %s  %s  %s",
+			s.ID,
+			s.CreatedAt.UTC().Format(time.RFC3339),
+			s.LastUsedAt.UTC().Format(time.RFC3339),
+			s.ExpiresAt.UTC().Format(time.RFC3339),
+			s.IP,
+			s.UserAgent))
+	}
+	a.print(sessions, lines...)
+	return nil
+}
+
+func (a *app) sessionRevoke(args []string) error {
+	rest, err := parse(flag.NewFlagSet("session revoke", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := a.admin.RevokeSession(a.ctx, rest[0]); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("session %s not found", rest[0])
+		}
+		return err
+	}
+
+	a.print(map[string]any{"revoked": 1}, "Session "+rest[0]+" revoked")
+	return nil
+}
+
+func (a *app) sessionRevokeAll(args []string) error {
+	rest, err := parse(flag.NewFlagSet("session revoke-all", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+
+	revoked, err := a.admin.RevokeSessions(a.ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	a.print(map[string]any{"revoked": revoked}, fmt.Sprintf("%d sessions of user %d (%s) revoked", revoked, u.ID, u.Email))
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type tokenInfo struct {
+	Header map[string]any `json:"header"`
+	Claims jwt.MapClaims  `json:"claims"`
+	Valid  bool           `json:"valid"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// tokenInspect decodes a token and verifies it against the current keys.
+func (a *app) tokenInspect(args []string) error {
+	rest, err := parse(flag.NewFlagSet("token inspect", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	raw := rest[0]
+
+	claims := jwt.MapClaims{}
+	token, _, err := jwt.NewParser(jwt.WithJSONNumber()).ParseUnverified(raw, claims)
+	if err != nil {
+		return fmt.Errorf("not a JWT: %w", err)
+	}
+
+	info := tokenInfo{Header: token.Header, Claims: claims}
+	isRefresh := claims["type"] == "refresh"
+	if _, err := a.tokens.ValidateToken(raw, isRefresh); err != nil {
+		info.Error = err.Error()
+	} else {
+		info.Valid = true
+	}
+
+	var lines []string
+	for _, m := range []map[string]any{token.Header, claims} {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lines = append(lines, fmt.Sprintf("%-5s %v", k+":", m[k]))
+		}
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		lines = append(lines, "expires: "+exp.UTC().String())
+	}
+	if info.Valid {
+		lines = append(lines, "status: valid")
+	} else {
+		lines = append(lines, "status: INVALID ("+info.Error+")")
+	}
+	a.print(info, lines...)
+	return nil
+}
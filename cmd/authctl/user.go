@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+type userResult struct {
+	User     *domain.User `json:"user"`
+	Password string       `json:"password,omitempty"`
+}
+
+func (a *app) userCreate(args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "email address")
+	name := fs.String("name", "", "display name")
+	role := fs.String("role", domain.RoleUser, "role: user or admin")
+	fromStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	if _, err := parse(fs, args, 0); err != nil || *email == "" {
+		return errUsage
+	}
+
+	pw, generated, err := password(*fromStdin)
+	if err != nil {
+		return err
+	}
+
+	u := &domain.User{Email: *email, Name: *name, Role: *role}
+	if err := a.admin.CreateUser(a.ctx, u, pw); err != nil {
+		return err
+	}
+
+	result := userResult{User: u}
+	lines := []string{fmt.Sprintf("Created user %d (%s) with role %s", u.ID, u.Email, u.Role)}
+	if generated {
+		result.Password = pw
+		lines = append(lines, "Password: "+pw)
+	}
+	a.print(result, lines...)
+	return nil
+}
+
+func (a *app) userShow(args []string) error {
+	rest, err := parse(flag.NewFlagSet("user show", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+
+	status := "enabled"
+	if u.DisabledAt != nil {
+		status = "disabled since " + u.DisabledAt.UTC().Format(time.RFC3339)
+	}
+	a.print(userResult{User: u},
+		fmt.Sprintf("ID:                   %d", u.ID),
+		fmt.Sprintf("Email:                %s", u.Email),
+		fmt.Sprintf("Name:                 %s", u.Name),
+		fmt.Sprintf("Role:                 %s", u.Role),
+		fmt.Sprintf("Status:               %s", status),
+		fmt.Sprintf("Must change password: %t", u.MustChangePassword),
+		fmt.Sprintf("Created:              %s", u.CreatedAt.UTC().Format(time.RFC3339)),
+	)
+	return nil
+}
+
+func (a *app) userSetPassword(args []string) error {
+	fs := flag.NewFlagSet("user set-password", flag.ContinueOnError)
+	fromStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+	mustChange := fs.Bool("must-change", false, "require a password change after the next login")
+	rest, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	pw, generated, err := password(*fromStdin)
+	if err != nil {
+		return err
+	}
+	return a.setPassword(rest[0], pw, generated, *mustChange)
+}
+
+// userResetPassword issues a generated temporary password.
+func (a *app) userResetPassword(args []string) error {
+	rest, err := parse(flag.NewFlagSet("user reset-password", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	pw, _, err := password(false)
+	if err != nil {
+		return err
+	}
+	return a.setPassword(rest[0], pw, true, true)
+}
+
+func (a *app) setPassword(ref, pw string, generated, mustChange bool) error {
+	u, err := a.findUser(ref)
+	if err != nil {
+		return err
+	}
+
+	if err := a.admin.SetPassword(a.ctx, u.ID, pw, mustChange); err != nil {
+		return err
+	}
+
+	u.MustChangePassword = mustChange
+	result := userResult{User: u}
+	lines := []string{fmt.Sprintf("Password of user %d (%s) set, all sessions revoked", u.ID, u.Email)}
+	if generated {
+		result.Password = pw
+		lines = append(lines, "Password: "+pw)
+	}
+	a.print(result, lines...)
+	return nil
+}
+
+func (a *app) userSetDisabled(args []string, disabled bool) error {
+	rest, err := parse(flag.NewFlagSet("user", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+
+	if err := a.admin.SetDisabled(a.ctx, u.ID, disabled); err != nil {
+		return err
+	}
+
+	u, err = a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("User %d (%s) enabled", u.ID, u.Email)
+	if disabled {
+		msg = fmt.Sprintf("User %d (%s) disabled, all sessions revoked", u.ID, u.Email)
+	}
+	a.print(userResult{User: u}, msg)
+	return nil
+}
+
+func (a *app) userSetRole(args []string) error {
+	rest, err := parse(flag.NewFlagSet("user set-role", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.findUser(rest[0])
+	if err != nil {
+		return err
+	}
+
+	if err := a.admin.SetRole(a.ctx, u.ID, rest[1]); err != nil {
+		return err
+	}
+
+	u.Role = rest[1]
+	a.print(userResult{User: u},
+		fmt.Sprintf("User %d (%s) now has role %s, all sessions revoked", u.ID, u.Email, u.Role))
+	return nil
+}
@@ -1,37 +1,400 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBHost           string `mapstructure:"DB_HOST"`
-	DBPort           string `mapstructure:"DB_PORT"`
-	DBUser           string `mapstructure:"DB_USER"`
-	DBPassword       string `mapstructure:"DB_PASSWORD"`
-	DBName           string `mapstructure:"DB_NAME"`
-	RedisHost        string `mapstructure:"REDIS_HOST"`
-	RedisPort        string `mapstructure:"REDIS_PORT"`
-	RedisPassword    string `mapstructure:"REDIS_PASSWORD"`
-	ServerPort       string `mapstructure:"SERVER_PORT"`
-	JWTSecret        string `mapstructure:"JWT_SECRET"`
-	JWTRefreshSecret string `mapstructure:"JWT_REFRESH_SECRET"`
-	JWTAccessExpiry  string `mapstructure:"JWT_ACCESS_EXPIRY"`
-	JWTRefreshExpiry string `mapstructure:"JWT_REFRESH_EXPIRY"`
+	// Storage driver: postgres, or sqlite for local development and tests
+	// with DB_PATH naming a file or ":memory:"
+	DBDriver string `mapstructure:"DB_DRIVER"`
+	DBPath   string `mapstructure:"DB_PATH"`
+
+	DBHost           string        `mapstructure:"DB_HOST"`
+	DBPort           int           `mapstructure:"DB_PORT"`
+	DBUser           string        `mapstructure:"DB_USER"`
+	DBPassword       string        `mapstructure:"DB_PASSWORD"`
+	DBName           string        `mapstructure:"DB_NAME"`
+	RedisHost        string        `mapstructure:"REDIS_HOST"`
+	RedisPort        int           `mapstructure:"REDIS_PORT"`
+	RedisPassword    string        `mapstructure:"REDIS_PASSWORD"`
+	ServerPort       int           `mapstructure:"SERVER_PORT"`
+	ServerBodyLimit  int           `mapstructure:"SERVER_BODY_LIMIT"`
+	JWTSecret        string        `mapstructure:"JWT_SECRET"`
+	JWTRefreshSecret string        `mapstructure:"JWT_REFRESH_SECRET"`
+	JWTAccessExpiry  time.Duration `mapstructure:"JWT_ACCESS_EXPIRY"`
+	JWTRefreshExpiry time.Duration `mapstructure:"JWT_REFRESH_EXPIRY"`
+
+	// Behind a load balancer, the header carrying the client address, e.g.
+	// X-Forwarded-For, and the comma-separated IPs or CIDRs of the proxies
+	// allowed to set it
+	ServerProxyHeader    string `mapstructure:"SERVER_PROXY_HEADER"`
+	ServerTrustedProxies string `mapstructure:"SERVER_TRUSTED_PROXIES"`
+
+	// Structured logs: LOG_FORMAT json or text, LOG_LEVEL debug, info, warn
+	// or error
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+
+	// Serve Prometheus metrics on /metrics of a separate listener, so they
+	// stay off the public port; the session count is refreshed every interval
+	MetricsEnabled          bool          `mapstructure:"METRICS_ENABLED"`
+	MetricsPort             int           `mapstructure:"METRICS_PORT"`
+	MetricsSessionsInterval time.Duration `mapstructure:"METRICS_SESSIONS_INTERVAL"`
+
+	// OpenTelemetry tracing: none, otlp or stdout. Without an endpoint the
+	// OTLP exporter reads the standard OTEL_EXPORTER_OTLP_* variables.
+	TracingExporter     string   `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint *url.URL `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName  string   `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio  float64  `mapstructure:"TRACING_SAMPLE_RATIO"`
+
+	// How long SIGTERM waits for in-flight requests before closing connections
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// How often signing keys rotated with authctl are picked up
+	SigningKeyReloadInterval time.Duration `mapstructure:"SIGNING_KEY_RELOAD_INTERVAL"`
+	// Track logins as sessions that authctl can list and revoke
+	SessionsEnabled bool `mapstructure:"SESSIONS_ENABLED"`
+
+	// Redis topology and connection. REDIS_CLUSTER_ADDRS selects cluster mode,
+	// REDIS_SENTINEL_MASTER Sentinel; otherwise REDIS_HOST/REDIS_PORT is used.
+	// Empty timeouts and a zero pool size keep the go-redis defaults.
+	RedisUsername         string        `mapstructure:"REDIS_USERNAME"`
+	RedisDB               int           `mapstructure:"REDIS_DB"`
+	RedisKeyPrefix        string        `mapstructure:"REDIS_KEY_PREFIX"`
+	RedisSentinelMaster   string        `mapstructure:"REDIS_SENTINEL_MASTER"`
+	RedisSentinelAddrs    string        `mapstructure:"REDIS_SENTINEL_ADDRS"`
+	RedisSentinelPassword string        `mapstructure:"REDIS_SENTINEL_PASSWORD"`
+	RedisClusterAddrs     string        `mapstructure:"REDIS_CLUSTER_ADDRS"`
+	RedisTLSEnabled       bool          `mapstructure:"REDIS_TLS_ENABLED"`
+	RedisTLSCAFile        string        `mapstructure:"REDIS_TLS_CA_FILE"`
+	RedisTLSServerName    string        `mapstructure:"REDIS_TLS_SERVER_NAME"`
+	RedisPoolSize         int           `mapstructure:"REDIS_POOL_SIZE"`
+	RedisMinIdleConns     int           `mapstructure:"REDIS_MIN_IDLE_CONNS"`
+	RedisDialTimeout      time.Duration `mapstructure:"REDIS_DIAL_TIMEOUT"`
+	RedisReadTimeout      time.Duration `mapstructure:"REDIS_READ_TIMEOUT"`
+	RedisWriteTimeout     time.Duration `mapstructure:"REDIS_WRITE_TIMEOUT"`
+
+	// Where users are kept: database, or memory for running without any
+	// external service (lost on restart, not shared between instances)
+	UserStore string `mapstructure:"USER_STORE"`
+
+	// Where revoked tokens are kept: redis, postgres or memory. The failure
+	// mode (closed, open or fallback) applies while that store is unreachable.
+	RevocationStore         string `mapstructure:"REVOCATION_STORE"`
+	RevocationFailureMode   string `mapstructure:"REVOCATION_FAILURE_MODE"`
+	RevocationFallbackStore string `mapstructure:"REVOCATION_FALLBACK_STORE"`
+
+	// In-process cache of Redis revocations, kept current over pub/sub
+	RevocationCacheEnabled    bool   `mapstructure:"REVOCATION_CACHE_ENABLED"`
+	RevocationCacheChannel    string `mapstructure:"REVOCATION_CACHE_CHANNEL"`
+	RevocationCacheMaxEntries int    `mapstructure:"REVOCATION_CACHE_MAX_ENTRIES"`
+
+	// Password hashing; argon2id memory is in KiB
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2Memory      uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+
+	// Pepper keys as "<version>:<key>" lines; the highest version peppers new hashes
+	PasswordPepperFile string `mapstructure:"PASSWORD_PEPPER_FILE"`
+
+	// Password policy; min strength is a 0-4 score (0 disables the check)
+	PasswordMinLength        int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength        int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireUppercase bool   `mapstructure:"PASSWORD_REQUIRE_UPPERCASE"`
+	PasswordRequireLowercase bool   `mapstructure:"PASSWORD_REQUIRE_LOWERCASE"`
+	PasswordRequireDigit     bool   `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol    bool   `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordBannedFile       string `mapstructure:"PASSWORD_BANNED_FILE"`
+	PasswordCheckSimilarity  bool   `mapstructure:"PASSWORD_CHECK_SIMILARITY"`
+	PasswordMinStrength      int    `mapstructure:"PASSWORD_MIN_STRENGTH"`
+
+	// Offline breached password corpus (HIBP range directory or sorted SHA-1 file)
+	PasswordBreachCorpus       string `mapstructure:"PASSWORD_BREACH_CORPUS"`
+	PasswordBreachMinCount     int    `mapstructure:"PASSWORD_BREACH_MIN_COUNT"`
+	PasswordBreachCheckOnLogin bool   `mapstructure:"PASSWORD_BREACH_CHECK_ON_LOGIN"`
+
+	// Password reuse prevention; depth 0 disables it, retention 0 keeps entries forever
+	PasswordHistoryDepth     int           `mapstructure:"PASSWORD_HISTORY_DEPTH"`
+	PasswordHistoryRetention time.Duration `mapstructure:"PASSWORD_HISTORY_RETENTION"`
+
+	// Cross-account failed login detection (credential stuffing / spraying)
+	LoginThreatEnabled           bool          `mapstructure:"LOGIN_THREAT_ENABLED"`
+	LoginThreatWindow            time.Duration `mapstructure:"LOGIN_THREAT_WINDOW"`
+	LoginThreatIPThreshold       int           `mapstructure:"LOGIN_THREAT_IP_THRESHOLD"`
+	LoginThreatCIDRThreshold     int           `mapstructure:"LOGIN_THREAT_CIDR_THRESHOLD"`
+	LoginThreatASNThreshold      int           `mapstructure:"LOGIN_THREAT_ASN_THRESHOLD"`
+	LoginThreatASNFile           string        `mapstructure:"LOGIN_THREAT_ASN_FILE"`
+	LoginThreatPasswordThreshold int           `mapstructure:"LOGIN_THREAT_PASSWORD_THRESHOLD"`
+	LoginThreatIPv4Prefix        int           `mapstructure:"LOGIN_THREAT_IPV4_PREFIX"`
+	LoginThreatIPv6Prefix        int           `mapstructure:"LOGIN_THREAT_IPV6_PREFIX"`
+	LoginThreatAction            string        `mapstructure:"LOGIN_THREAT_ACTION"`
+	LoginThreatBlockDuration     time.Duration `mapstructure:"LOGIN_THREAT_BLOCK_DURATION"`
+	// Siteverify endpoint (Turnstile, hCaptcha or reCAPTCHA) checking the
+	// challenge responses of flagged sources with LOGIN_THREAT_ACTION=challenge
+	LoginThreatChallengeVerifyURL string `mapstructure:"LOGIN_THREAT_CHALLENGE_VERIFY_URL"`
+	LoginThreatChallengeSecret    string `mapstructure:"LOGIN_THREAT_CHALLENGE_SECRET"`
+
+	// Encryption of sensitive columns at rest: none, or local with a keyring
+	// file of "<version>:<base64 key>" lines, the highest version wrapping
+	// new data keys
+	EncryptionKeyProvider  string `mapstructure:"ENCRYPTION_KEY_PROVIDER"`
+	EncryptionLocalKeyring string `mapstructure:"ENCRYPTION_LOCAL_KEYRING"`
+
+	// Audit hash chain checkpoints, every interval events and of the chain
+	// head every sign interval and at shutdown; the signing key defaults to
+	// JWT_SECRET
+	AuditSigningKey         string        `mapstructure:"AUDIT_SIGNING_KEY"`
+	AuditCheckpointInterval int           `mapstructure:"AUDIT_CHECKPOINT_INTERVAL"`
+	AuditHeadSignInterval   time.Duration `mapstructure:"AUDIT_HEAD_SIGN_INTERVAL"`
+
+	// Apply pending SQL migrations at startup instead of refusing to start
+	MigrateOnStart bool `mapstructure:"MIGRATE_ON_START"`
+
+	// Outbound webhooks
+	WebhookWorkerEnabled bool          `mapstructure:"WEBHOOK_WORKER_ENABLED"`
+	WebhookPollInterval  time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+	WebhookTimeout       time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookBackoffBase   time.Duration `mapstructure:"WEBHOOK_BACKOFF_BASE"`
+	WebhookMaxAttempts   int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	// Allow deliveries to loopback, private and link-local addresses, e.g.
+	// for local development; refused by default to prevent SSRF
+	WebhookAllowPrivateNetworks bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+
+	// Transactional outbox relay; sinks is a comma-separated list of
+	// webhook, redis_stream and stdout
+	OutboxRelayEnabled   bool          `mapstructure:"OUTBOX_RELAY_ENABLED"`
+	OutboxSinks          string        `mapstructure:"OUTBOX_SINKS"`
+	OutboxPollInterval   time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize      int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxLease          time.Duration `mapstructure:"OUTBOX_LEASE"`
+	OutboxBackoffBase    time.Duration `mapstructure:"OUTBOX_BACKOFF_BASE"`
+	OutboxMaxAttempts    int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxRedisStream    string        `mapstructure:"OUTBOX_REDIS_STREAM"`
+	OutboxRedisStreamLen int64         `mapstructure:"OUTBOX_REDIS_STREAM_MAXLEN"`
 }
 
+// LoadConfig loads the configuration and validates it.
 func LoadConfig() (Config, error) {
+	config, err := Load()
+	if err != nil {
+		return config, err
+	}
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return config, nil
+}
+
+// Load reads the configuration without validating it. Environment variables
+// take precedence over a .env file in the working directory, which takes
+// precedence over the YAML file named by CONFIG_FILE, which takes precedence
+// over the defaults.
+func Load() (Config, error) {
 	var config Config
-	viper.SetConfigFile(".env")
-	viper.AutomaticEnv()
+	v := viper.New()
+	v.AutomaticEnv()
+
+	// AutomaticEnv only applies to keys viper already knows about, so bind
+	// every field: one without a default or file value would otherwise
+	// ignore its environment variable.
+	t := reflect.TypeOf(config)
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			if err := v.BindEnv(key); err != nil {
+				return config, err
+			}
+		}
+	}
+
+	v.SetDefault("DB_HOST", "localhost")
+	v.SetDefault("DB_PORT", 5432)
+	v.SetDefault("DB_USER", "postgres")
+	v.SetDefault("DB_PASSWORD", "")
+	v.SetDefault("DB_NAME", "auth_db")
+	v.SetDefault("REDIS_HOST", "localhost")
+	v.SetDefault("REDIS_PORT", 6379)
+	v.SetDefault("REDIS_PASSWORD", "")
+	v.SetDefault("SERVER_PORT", 8080)
+	v.SetDefault("SERVER_BODY_LIMIT", 16*1024)
+	v.SetDefault("SERVER_PROXY_HEADER", "")
+	v.SetDefault("SERVER_TRUSTED_PROXIES", "")
+	v.SetDefault("JWT_SECRET", "")
+	v.SetDefault("JWT_REFRESH_SECRET", "")
+	v.SetDefault("JWT_ACCESS_EXPIRY", "15m")
+	v.SetDefault("JWT_REFRESH_EXPIRY", "24h")
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "json")
+	v.SetDefault("METRICS_ENABLED", true)
+	v.SetDefault("METRICS_PORT", 9090)
+	v.SetDefault("METRICS_SESSIONS_INTERVAL", "30s")
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	v.SetDefault("TRACING_SERVICE_NAME", "go-auth-service")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("SHUTDOWN_TIMEOUT", "20s")
+	v.SetDefault("SIGNING_KEY_RELOAD_INTERVAL", "1m")
+	v.SetDefault("SESSIONS_ENABLED", true)
+	v.SetDefault("DB_DRIVER", "postgres")
+	v.SetDefault("DB_PATH", "auth.db")
+	v.SetDefault("MIGRATE_ON_START", false)
+	v.SetDefault("REDIS_USERNAME", "")
+	v.SetDefault("REDIS_DB", 0)
+	v.SetDefault("REDIS_KEY_PREFIX", "")
+	v.SetDefault("REDIS_SENTINEL_MASTER", "")
+	v.SetDefault("REDIS_SENTINEL_ADDRS", "")
+	v.SetDefault("REDIS_SENTINEL_PASSWORD", "")
+	v.SetDefault("REDIS_CLUSTER_ADDRS", "")
+	v.SetDefault("REDIS_TLS_ENABLED", false)
+	v.SetDefault("REDIS_TLS_CA_FILE", "")
+	v.SetDefault("REDIS_TLS_SERVER_NAME", "")
+	v.SetDefault("REDIS_POOL_SIZE", 0)
+	v.SetDefault("REDIS_MIN_IDLE_CONNS", 0)
+	v.SetDefault("REDIS_DIAL_TIMEOUT", "")
+	v.SetDefault("REDIS_READ_TIMEOUT", "")
+	v.SetDefault("REDIS_WRITE_TIMEOUT", "")
+	v.SetDefault("USER_STORE", "database")
+	v.SetDefault("REVOCATION_STORE", "redis")
+	v.SetDefault("REVOCATION_FAILURE_MODE", "closed")
+	v.SetDefault("REVOCATION_FALLBACK_STORE", "postgres")
+	v.SetDefault("REVOCATION_CACHE_ENABLED", true)
+	v.SetDefault("REVOCATION_CACHE_CHANNEL", "revocations")
+	v.SetDefault("REVOCATION_CACHE_MAX_ENTRIES", 1000000)
+	v.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	v.SetDefault("PASSWORD_BCRYPT_COST", 12)
+	v.SetDefault("PASSWORD_ARGON2_MEMORY", 64*1024)
+	v.SetDefault("PASSWORD_ARGON2_ITERATIONS", 3)
+	v.SetDefault("PASSWORD_ARGON2_PARALLELISM", 2)
+	v.SetDefault("PASSWORD_PEPPER_FILE", "")
+	v.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	v.SetDefault("PASSWORD_MAX_LENGTH", 128)
+	v.SetDefault("PASSWORD_REQUIRE_UPPERCASE", false)
+	v.SetDefault("PASSWORD_REQUIRE_LOWERCASE", false)
+	v.SetDefault("PASSWORD_REQUIRE_DIGIT", false)
+	v.SetDefault("PASSWORD_REQUIRE_SYMBOL", false)
+	v.SetDefault("PASSWORD_BANNED_FILE", "")
+	v.SetDefault("PASSWORD_CHECK_SIMILARITY", true)
+	v.SetDefault("PASSWORD_MIN_STRENGTH", 2)
+	v.SetDefault("PASSWORD_BREACH_CORPUS", "")
+	v.SetDefault("PASSWORD_BREACH_MIN_COUNT", 1)
+	v.SetDefault("PASSWORD_BREACH_CHECK_ON_LOGIN", false)
+	v.SetDefault("PASSWORD_HISTORY_DEPTH", 5)
+	v.SetDefault("PASSWORD_HISTORY_RETENTION", "8760h")
+	v.SetDefault("LOGIN_THREAT_ENABLED", true)
+	v.SetDefault("LOGIN_THREAT_WINDOW", "10m")
+	v.SetDefault("LOGIN_THREAT_IP_THRESHOLD", 20)
+	v.SetDefault("LOGIN_THREAT_CIDR_THRESHOLD", 50)
+	v.SetDefault("LOGIN_THREAT_ASN_THRESHOLD", 200)
+	v.SetDefault("LOGIN_THREAT_ASN_FILE", "")
+	v.SetDefault("LOGIN_THREAT_PASSWORD_THRESHOLD", 10)
+	v.SetDefault("LOGIN_THREAT_IPV4_PREFIX", 24)
+	v.SetDefault("LOGIN_THREAT_IPV6_PREFIX", 48)
+	// Blocking by source IP needs the client addresses, which behind a proxy
+	// only SERVER_PROXY_HEADER provides.
+	v.SetDefault("LOGIN_THREAT_ACTION", "alert")
+	v.SetDefault("LOGIN_THREAT_BLOCK_DURATION", "15m")
+	v.SetDefault("LOGIN_THREAT_CHALLENGE_VERIFY_URL", "")
+	v.SetDefault("LOGIN_THREAT_CHALLENGE_SECRET", "")
+	v.SetDefault("ENCRYPTION_KEY_PROVIDER", "none")
+	v.SetDefault("ENCRYPTION_LOCAL_KEYRING", "")
+	v.SetDefault("AUDIT_SIGNING_KEY", "")
+	v.SetDefault("AUDIT_CHECKPOINT_INTERVAL", 1000)
+	v.SetDefault("AUDIT_HEAD_SIGN_INTERVAL", "1m")
+	v.SetDefault("WEBHOOK_WORKER_ENABLED", true)
+	v.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	v.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	v.SetDefault("WEBHOOK_BACKOFF_BASE", "30s")
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	v.SetDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	v.SetDefault("OUTBOX_RELAY_ENABLED", true)
+	v.SetDefault("OUTBOX_SINKS", "webhook")
+	v.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	v.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	v.SetDefault("OUTBOX_LEASE", "5m")
+	v.SetDefault("OUTBOX_BACKOFF_BASE", "5s")
+	v.SetDefault("OUTBOX_MAX_ATTEMPTS", 20)
+	v.SetDefault("OUTBOX_REDIS_STREAM", "auth:events")
+	v.SetDefault("OUTBOX_REDIS_STREAM_MAXLEN", 100000)
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return config, fmt.Errorf("failed to read CONFIG_FILE %s: %w", path, err)
+		}
+	}
+
+	// The .env file is optional; env vars may be set directly.
+	v.SetConfigFile(".env")
+	if err := v.MergeInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return config, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	if err := readSecretFiles(v); err != nil {
+		return config, err
+	}
+
+	if err := v.Unmarshal(&config, viper.DecodeHook(decodeHook())); err != nil {
+		return config, err
+	}
+	if config.AuditSigningKey == "" {
+		config.AuditSigningKey = config.JWTSecret
+	}
+	return config, nil
+}
+
+// readSecretFiles sets each secret from the file named by its _FILE variant,
+// such as JWT_SECRET_FILE, the way Docker and Kubernetes mount secrets.
+func readSecretFiles(v *viper.Viper) error {
+	for _, key := range secretKeys {
+		fileKey := key + "_FILE"
+		if err := v.BindEnv(fileKey); err != nil {
+			return err
+		}
+		path := v.GetString(fileKey)
+		if path == "" {
+			continue
+		}
+		if v.GetString(key) != "" {
+			return fmt.Errorf("set only one of %s and %s", key, fileKey)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileKey, err)
+		}
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+// decodeHook parses durations and URLs, leaving empty values at zero and nil.
+func decodeHook() mapstructure.DecodeHookFunc {
+	durationType := reflect.TypeOf(time.Duration(0))
+	urlType := reflect.TypeOf(&url.URL{})
 
-	if err := viper.ReadInConfig(); err != nil {
-		// It's okay if config file doesn't exist, we might be using env vars
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return config, err
+	emptyToZero := func(f, t reflect.Type, data any) (any, error) {
+		if s, ok := data.(string); ok && s == "" && (t == durationType || t == urlType) {
+			return reflect.Zero(t).Interface(), nil
 		}
+		return data, nil
 	}
 
-	err := viper.Unmarshal(&config)
-	return config, err
+	return mapstructure.ComposeDecodeHookFunc(
+		emptyToZero,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToURLHookFunc(),
+	)
 }
@@ -0,0 +1,174 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret        = "0123456789abcdef0123456789abcdef"
+	testRefreshSecret = "fedcba9876543210fedcba9876543210"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Layering", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		yaml := "db_host: db.internal\njwt_access_expiry: 5m\nserver_port: 9000\ntracing_otlp_endpoint: http://collector:4318\n"
+		require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+		t.Setenv("CONFIG_FILE", path)
+		t.Setenv("SERVER_PORT", "9100")
+		t.Setenv("JWT_SECRET", testSecret)
+
+		cfg, err := config.Load()
+
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", cfg.DBHost, "from the file")
+		assert.Equal(t, 5*time.Minute, cfg.JWTAccessExpiry, "from the file")
+		assert.Equal(t, 9100, cfg.ServerPort, "env overrides the file")
+		assert.Equal(t, testSecret, cfg.JWTSecret, "env without a default or file value")
+		assert.Equal(t, 24*time.Hour, cfg.JWTRefreshExpiry, "default")
+		assert.Equal(t, "collector:4318", cfg.TracingOTLPEndpoint.Host)
+		assert.Zero(t, cfg.RedisDialTimeout)
+	})
+
+	t.Run("SecretFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwt_secret")
+		require.NoError(t, os.WriteFile(path, []byte(testSecret+"\n"), 0o600))
+		t.Setenv("JWT_SECRET_FILE", path)
+
+		cfg, err := config.Load()
+
+		require.NoError(t, err)
+		assert.Equal(t, testSecret, cfg.JWTSecret, "trailing newline is trimmed")
+		assert.Equal(t, testSecret, cfg.AuditSigningKey)
+
+		t.Setenv("JWT_SECRET", testSecret)
+		_, err = config.Load()
+		assert.ErrorContains(t, err, "set only one of JWT_SECRET and JWT_SECRET_FILE")
+	})
+
+	t.Run("InvalidDuration", func(t *testing.T) {
+		t.Setenv("JWT_ACCESS_EXPIRY", "fifteen minutes")
+
+		_, err := config.Load()
+
+		assert.ErrorContains(t, err, "JWT_ACCESS_EXPIRY")
+	})
+
+	t.Run("MissingConfigFile", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+		_, err := config.Load()
+
+		assert.ErrorContains(t, err, "CONFIG_FILE")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("JWT_REFRESH_SECRET", testRefreshSecret)
+	valid, err := config.Load()
+	require.NoError(t, err)
+	require.NoError(t, valid.Validate())
+
+	t.Run("ShortSecret", func(t *testing.T) {
+		cfg := valid
+		cfg.JWTSecret = "secret"
+
+		assert.ErrorContains(t, cfg.Validate(), "JWT_SECRET must be at least 32 bytes")
+	})
+
+	t.Run("RefreshExpiryNotLonger", func(t *testing.T) {
+		cfg := valid
+		cfg.JWTRefreshExpiry = cfg.JWTAccessExpiry
+
+		assert.ErrorContains(t, cfg.Validate(), "JWT_REFRESH_EXPIRY")
+	})
+
+	t.Run("ReportsEveryProblem", func(t *testing.T) {
+		cfg := valid
+		cfg.ServerPort = 0
+		cfg.RevocationStore = "etcd"
+
+		err := cfg.Validate()
+
+		assert.ErrorContains(t, err, "SERVER_PORT")
+		assert.ErrorContains(t, err, "REVOCATION_STORE")
+	})
+
+	t.Run("LoginThreatPrefixes", func(t *testing.T) {
+		cfg := valid
+		cfg.LoginThreatIPv4Prefix = 33
+		cfg.LoginThreatIPv6Prefix = 0
+
+		err := cfg.Validate()
+
+		assert.ErrorContains(t, err, "LOGIN_THREAT_IPV4_PREFIX must be between 1 and 32, got 33")
+		assert.ErrorContains(t, err, "LOGIN_THREAT_IPV6_PREFIX must be between 1 and 128, got 0")
+	})
+
+	t.Run("LoginThreatChallenge", func(t *testing.T) {
+		cfg := valid
+		cfg.LoginThreatAction = "challenge"
+
+		err := cfg.Validate()
+
+		assert.ErrorContains(t, err, "LOGIN_THREAT_CHALLENGE_VERIFY_URL")
+		assert.ErrorContains(t, err, "LOGIN_THREAT_CHALLENGE_SECRET")
+
+		cfg.LoginThreatChallengeVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+		cfg.LoginThreatChallengeSecret = "secret"
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("TrustedProxies", func(t *testing.T) {
+		cfg := valid
+		cfg.ServerProxyHeader = "X-Forwarded-For"
+
+		assert.ErrorContains(t, cfg.Validate(), "SERVER_TRUSTED_PROXIES is required with SERVER_PROXY_HEADER")
+
+		cfg.ServerTrustedProxies = "10.0.0.0/8, 192.168.1.10,lb.internal"
+		assert.ErrorContains(t, cfg.Validate(), `SERVER_TRUSTED_PROXIES must list IP addresses or CIDR ranges, got "lb.internal"`)
+
+		cfg.ServerTrustedProxies = "10.0.0.0/8, 192.168.1.10, fd00::/8"
+		assert.NoError(t, cfg.Validate())
+
+		cfg.ServerProxyHeader = ""
+		assert.ErrorContains(t, cfg.Validate(), "SERVER_TRUSTED_PROXIES has no effect without SERVER_PROXY_HEADER")
+	})
+
+	t.Run("MetricsOnPublicPort", func(t *testing.T) {
+		cfg := valid
+		cfg.MetricsPort = cfg.ServerPort
+
+		assert.ErrorContains(t, cfg.Validate(), "METRICS_PORT must differ from SERVER_PORT")
+
+		cfg.MetricsEnabled = false
+		assert.NoError(t, cfg.Validate())
+	})
+}
+
+func TestSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("DB_PASSWORD", "")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	values := map[string]string{}
+	for _, s := range cfg.Settings() {
+		values[s.Key] = s.Value
+	}
+
+	assert.Equal(t, "[REDACTED]", values["JWT_SECRET"])
+	assert.Equal(t, "[REDACTED]", values["AUDIT_SIGNING_KEY"], "defaults to JWT_SECRET")
+	assert.Equal(t, "", values["DB_PASSWORD"], "empty secrets are shown as unset")
+	assert.Equal(t, "15m0s", values["JWT_ACCESS_EXPIRY"])
+	assert.NotContains(t, values, "")
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MinSecretLength is the shortest accepted JWT secret, in bytes: HS256 keys
+// should be at least as long as the hash.
+const MinSecretLength = 32
+
+// secretKeys can be read from files and are printed as [REDACTED] by
+// Settings.
+var secretKeys = []string{
+	"DB_PASSWORD",
+	"REDIS_PASSWORD",
+	"REDIS_SENTINEL_PASSWORD",
+	"JWT_SECRET",
+	"JWT_REFRESH_SECRET",
+	"AUDIT_SIGNING_KEY",
+	"LOGIN_THREAT_CHALLENGE_SECRET",
+}
+
+// Validate reports every setting that would stop the service from working,
+// so startup fails with all of them instead of the first request failing
+// with one.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value))
+	}
+	positive := func(key string, d time.Duration) {
+		check(d > 0, "%s must be a positive duration, got %s", key, d)
+	}
+	port := func(key string, p int) {
+		check(p > 0 && p <= 65535, "%s must be a port between 1 and 65535, got %d", key, p)
+	}
+
+	check(len(c.JWTSecret) >= MinSecretLength, "JWT_SECRET must be at least %d bytes", MinSecretLength)
+	check(len(c.JWTRefreshSecret) >= MinSecretLength, "JWT_REFRESH_SECRET must be at least %d bytes", MinSecretLength)
+	check(c.JWTSecret == "" || c.JWTSecret != c.JWTRefreshSecret, "JWT_REFRESH_SECRET must differ from JWT_SECRET")
+	positive("JWT_ACCESS_EXPIRY", c.JWTAccessExpiry)
+	check(c.JWTRefreshExpiry > c.JWTAccessExpiry, "JWT_REFRESH_EXPIRY (%s) must be longer than JWT_ACCESS_EXPIRY (%s)", c.JWTRefreshExpiry, c.JWTAccessExpiry)
+
+	port("SERVER_PORT", c.ServerPort)
+	if c.MetricsEnabled {
+		port("METRICS_PORT", c.MetricsPort)
+		check(c.MetricsPort != c.ServerPort, "METRICS_PORT must differ from SERVER_PORT, so metrics stay off the public port")
+		positive("METRICS_SESSIONS_INTERVAL", c.MetricsSessionsInterval)
+	}
+	check(c.ServerBodyLimit > 0, "SERVER_BODY_LIMIT must be a positive number of bytes, got %d", c.ServerBodyLimit)
+	// A proxy header trusted from any peer lets every client pick its IP.
+	check(c.ServerProxyHeader == "" || c.ServerTrustedProxies != "", "SERVER_TRUSTED_PROXIES is required with SERVER_PROXY_HEADER")
+	check(c.ServerTrustedProxies == "" || c.ServerProxyHeader != "", "SERVER_TRUSTED_PROXIES has no effect without SERVER_PROXY_HEADER")
+	for _, proxy := range strings.Split(c.ServerTrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "SERVER_TRUSTED_PROXIES must list IP addresses or CIDR ranges, got %q", proxy)
+	}
+	oneOf("DB_DRIVER", c.DBDriver, "postgres", "sqlite")
+	switch c.DBDriver {
+	case "postgres":
+		check(c.DBHost != "", "DB_HOST is required")
+		check(c.DBName != "", "DB_NAME is required")
+		check(c.DBUser != "", "DB_USER is required")
+		port("DB_PORT", c.DBPort)
+	case "sqlite":
+		check(c.DBPath != "", "DB_PATH is required")
+	}
+	if c.RedisClusterAddrs == "" && c.RedisSentinelMaster == "" {
+		port("REDIS_PORT", c.RedisPort)
+	}
+	check(c.RedisDialTimeout >= 0 && c.RedisReadTimeout >= 0 && c.RedisWriteTimeout >= 0, "Redis timeouts must not be negative")
+
+	oneOf("LOG_LEVEL", strings.ToLower(c.LogLevel), "debug", "info", "warn", "error")
+	oneOf("LOG_FORMAT", c.LogFormat, "json", "text")
+
+	oneOf("TRACING_EXPORTER", c.TracingExporter, "none", "otlp", "stdout")
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.TracingSampleRatio)
+	if u := c.TracingOTLPEndpoint; u != nil {
+		check((u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "TRACING_OTLP_ENDPOINT must be an http or https URL, got %q", u.Redacted())
+	}
+
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	positive("SIGNING_KEY_RELOAD_INTERVAL", c.SigningKeyReloadInterval)
+	positive("AUDIT_HEAD_SIGN_INTERVAL", c.AuditHeadSignInterval)
+
+	oneOf("USER_STORE", c.UserStore, "database", "memory")
+	oneOf("REVOCATION_STORE", c.RevocationStore, "redis", "postgres", "memory")
+	oneOf("REVOCATION_FAILURE_MODE", c.RevocationFailureMode, "closed", "open", "fallback")
+	if c.RevocationFailureMode == "fallback" {
+		oneOf("REVOCATION_FALLBACK_STORE", c.RevocationFallbackStore, "redis", "postgres", "memory")
+		check(c.RevocationFallbackStore != c.RevocationStore, "REVOCATION_FALLBACK_STORE must differ from REVOCATION_STORE")
+	}
+
+	oneOf("ENCRYPTION_KEY_PROVIDER", c.EncryptionKeyProvider, "none", "local")
+	if c.EncryptionKeyProvider == "local" {
+		check(c.EncryptionLocalKeyring != "", "ENCRYPTION_LOCAL_KEYRING is required with the local key provider")
+	}
+
+	check(c.PasswordHistoryRetention >= 0, "PASSWORD_HISTORY_RETENTION must not be negative")
+	if c.LoginThreatEnabled {
+		positive("LOGIN_THREAT_WINDOW", c.LoginThreatWindow)
+		positive("LOGIN_THREAT_BLOCK_DURATION", c.LoginThreatBlockDuration)
+		check(c.LoginThreatIPv4Prefix >= 1 && c.LoginThreatIPv4Prefix <= 32, "LOGIN_THREAT_IPV4_PREFIX must be between 1 and 32, got %d", c.LoginThreatIPv4Prefix)
+		check(c.LoginThreatIPv6Prefix >= 1 && c.LoginThreatIPv6Prefix <= 128, "LOGIN_THREAT_IPV6_PREFIX must be between 1 and 128, got %d", c.LoginThreatIPv6Prefix)
+		oneOf("LOGIN_THREAT_ACTION", strings.ToLower(c.LoginThreatAction), "alert", "challenge", "block")
+		if strings.EqualFold(c.LoginThreatAction, "challenge") {
+			u, err := url.Parse(c.LoginThreatChallengeVerifyURL)
+			check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "", "LOGIN_THREAT_CHALLENGE_VERIFY_URL must be an absolute http(s) URL with LOGIN_THREAT_ACTION=challenge")
+			check(c.LoginThreatChallengeSecret != "", "LOGIN_THREAT_CHALLENGE_SECRET is required with LOGIN_THREAT_ACTION=challenge")
+		}
+	}
+
+	positive("WEBHOOK_POLL_INTERVAL", c.WebhookPollInterval)
+	positive("WEBHOOK_TIMEOUT", c.WebhookTimeout)
+	positive("WEBHOOK_BACKOFF_BASE", c.WebhookBackoffBase)
+	positive("OUTBOX_POLL_INTERVAL", c.OutboxPollInterval)
+	check(c.OutboxBatchSize > 0, "OUTBOX_BATCH_SIZE must be positive, got %d", c.OutboxBatchSize)
+	positive("OUTBOX_LEASE", c.OutboxLease)
+	positive("OUTBOX_BACKOFF_BASE", c.OutboxBackoffBase)
+	check(c.OutboxMaxAttempts > 0, "OUTBOX_MAX_ATTEMPTS must be positive, got %d", c.OutboxMaxAttempts)
+	for _, sink := range strings.Split(c.OutboxSinks, ",") {
+		if sink = strings.TrimSpace(sink); sink != "" {
+			oneOf("OUTBOX_SINKS", sink, "webhook", "redis_stream", "stdout")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Setting is one configuration key and its value as printed by Settings.
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Settings lists every key with its effective value, in declaration order,
+// with secrets and URL passwords redacted.
+func (c Config) Settings() []Setting {
+	v := reflect.ValueOf(c)
+	t := v.Type()
+
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		var value string
+		switch f := v.Field(i).Interface().(type) {
+		case *url.URL:
+			if f != nil {
+				value = f.Redacted()
+			}
+		default:
+			value = fmt.Sprint(f)
+		}
+		if slices.Contains(secretKeys, key) && value != "" {
+			value = "[REDACTED]"
+		}
+		settings = append(settings, Setting{Key: key, Value: value})
+	}
+	return settings
+}
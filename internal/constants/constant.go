@@ -1,5 +1,6 @@
 package constant
 
 const (
-	STR_BLACKLIST = "blacklist:"
+	STR_BLACKLIST    = "blacklist:"
+	STR_LOGIN_THREAT = "loginthreat:"
 )
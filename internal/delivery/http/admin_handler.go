@@ -0,0 +1,170 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminHandler struct {
+	auditUsecase     domain.AuditUsecase
+	auditLogger      domain.AuditLogger
+	webhookUsecase   domain.WebhookUsecase
+	revocationHealth domain.RevocationHealth
+}
+
+func NewAdminHandler(auditUsecase domain.AuditUsecase, auditLogger domain.AuditLogger, webhookUsecase domain.WebhookUsecase, revocationHealth domain.RevocationHealth) *AdminHandler {
+	return &AdminHandler{
+		auditUsecase:     auditUsecase,
+		auditLogger:      auditLogger,
+		webhookUsecase:   webhookUsecase,
+		revocationHealth: revocationHealth,
+	}
+}
+
+// ListAudit supports the query parameters page, page_size, user_id, action,
+// outcome, email, ip, from and to (RFC 3339).
+func (h *AdminHandler) ListAudit(c *fiber.Ctx) error {
+	filter := domain.AuditFilter{
+		Action:   c.Query("action"),
+		Outcome:  c.Query("outcome"),
+		Email:    c.Query("email"),
+		IP:       c.Query("ip"),
+		Page:     c.QueryInt("page", 1),
+		PageSize: c.QueryInt("page_size", 0),
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return domain.NewValidationError("user_id", "uint", "must be a positive integer")
+		}
+		userID := uint(id)
+		filter.UserID = &userID
+	}
+
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return domain.NewValidationError(param, "datetime", "must be an RFC 3339 timestamp")
+			}
+			*target = t
+		}
+	}
+
+	ctx := requestContext(c)
+	events, total, err := h.auditUsecase.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	// Reading the audit log is itself audited.
+	adminID, _ := c.Locals("userID").(uint)
+	h.auditLogger.Log(ctx, &domain.AuditEvent{
+		Action:  domain.AuditActionAuditQuery,
+		Outcome: domain.AuditOutcomeSuccess,
+		UserID:  &adminID,
+		Reason:  string(c.Request().URI().QueryString()),
+	})
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	return c.JSON(fiber.Map{
+		"items": events,
+		"total": total,
+		"page":  filter.Page,
+		"count": len(events),
+	})
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,max=2048,http_url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,max=32,dive,required,max=64"`
+	Secret     string   `json:"secret" validate:"max=256"`
+}
+
+func (h *AdminHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req CreateWebhookRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	sub, secret, err := h.webhookUsecase.CreateSubscription(requestContext(c), req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"subscription": sub,
+		"secret":       secret,
+	})
+}
+
+func (h *AdminHandler) ListWebhooks(c *fiber.Ctx) error {
+	subs, err := h.webhookUsecase.ListSubscriptions(requestContext(c))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{"items": subs})
+}
+
+func (h *AdminHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return domain.NewValidationError("id", "uint", "must be a positive integer")
+	}
+
+	if err := h.webhookUsecase.DeleteSubscription(requestContext(c), uint(id)); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// ListWebhookDeliveries defaults to the dead-letter view; pass status=pending
+// or status=delivered to inspect the rest of the queue.
+func (h *AdminHandler) ListWebhookDeliveries(c *fiber.Ctx) error {
+	status := c.Query("status", domain.WebhookDeliveryDead)
+	page := c.QueryInt("page", 1)
+
+	deliveries, total, err := h.webhookUsecase.ListDeliveries(requestContext(c), status, page, c.QueryInt("page_size", 0))
+	if err != nil {
+		return err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	return c.JSON(fiber.Map{
+		"items": deliveries,
+		"total": total,
+		"page":  page,
+		"count": len(deliveries),
+	})
+}
+
+func (h *AdminHandler) RetryWebhookDelivery(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return domain.NewValidationError("id", "uint", "must be a positive integer")
+	}
+
+	delivery, err := h.webhookUsecase.RetryDelivery(requestContext(c), uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(delivery)
+}
+
+func (h *AdminHandler) RevocationStatus(c *fiber.Ctx) error {
+	return c.JSON(h.revocationHealth.Status())
+}
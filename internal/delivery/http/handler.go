@@ -1,6 +1,9 @@
 package http
 
 import (
+	"context"
+	"errors"
+
 	"go-auth-service/internal/domain"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,20 +17,36 @@ func NewAuthHandler(authUsecase domain.AuthUsecase) *AuthHandler {
 	return &AuthHandler{authUsecase: authUsecase}
 }
 
+// requestContext carries the caller's network details into the usecase layer.
+func requestContext(c *fiber.Ctx) context.Context {
+	return domain.ContextWithClientInfo(c.UserContext(), domain.ClientInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	})
+}
+
+// currentUserID is set by AuthMiddleware.Protected; its absence is a routing bug.
+func currentUserID(c *fiber.Ctx) (uint, error) {
+	userID, ok := c.Locals("userID").(uint)
+	if !ok {
+		return 0, errors.New("user ID not found in context")
+	}
+	return userID, nil
+}
+
+// Request bodies are checked against their validate tags by parseBody.
+// Passwords are only bounded to cap the hashing work; the password policy
+// decides what is acceptable.
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Email    string `json:"email" validate:"required,max=254,email"`
+	Password string `json:"password" validate:"required,max=1024"`
+	Name     string `json:"name" validate:"max=100,personname"`
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
-	}
-
-	if req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Email and password are required"})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
 	user := &domain.User{
@@ -36,27 +55,36 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		Name:     req.Name,
 	}
 
-	if err := h.authUsecase.Register(c.Context(), user); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	if err := h.authUsecase.Register(requestContext(c), user); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "User registered successfully"})
 }
 
+// LoginRequest does not check the email's format: an address that fails it
+// cannot belong to an account, and the answer must stay invalid_credentials.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,max=254"`
+	Password string `json:"password" validate:"required,max=1024"`
+	// ChallengeResponse is only needed after a challenge_required error.
+	ChallengeResponse string `json:"challenge_response,omitempty" validate:"max=4096"`
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
-	accessToken, refreshToken, err := h.authUsecase.Login(c.Context(), req.Email, req.Password)
+	ctx := requestContext(c)
+	client := domain.ClientInfoFromContext(ctx)
+	client.ChallengeResponse = req.ChallengeResponse
+	ctx = domain.ContextWithClientInfo(ctx, client)
+
+	accessToken, refreshToken, err := h.authUsecase.Login(ctx, req.Email, req.Password)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -66,18 +94,18 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 }
 
 type RefreshRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" validate:"required,jwt"`
 }
 
 func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 	var req RefreshRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
-	accessToken, refreshToken, err := h.authUsecase.RefreshToken(c.Context(), req.RefreshToken)
+	accessToken, refreshToken, err := h.authUsecase.RefreshToken(requestContext(c), req.RefreshToken)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -86,38 +114,110 @@ func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 	})
 }
 
+// LogoutRequest is optional: without a refresh token only the access token
+// is revoked.
 type LogoutRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" validate:"omitempty,jwt"`
 }
 
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	var req LogoutRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return err
+		}
 	}
 
 	accessToken, ok := c.Locals("accessToken").(string)
 	if !ok {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Access token not found in context"})
+		return errors.New("access token not found in context")
 	}
 
-	if err := h.authUsecase.Logout(c.Context(), accessToken, req.RefreshToken); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Logout failed"})
+	if err := h.authUsecase.Logout(requestContext(c), accessToken, req.RefreshToken); err != nil {
+		return err
 	}
 
 	return c.JSON(fiber.Map{"message": "Logged out successfully"})
 }
 
 func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
-	userID, ok := c.Locals("userID").(uint)
-	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User ID not found in context"})
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.authUsecase.GetMe(requestContext(c), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(user)
+}
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" validate:"max=100,personname"`
+}
+
+func (h *AuthHandler) UpdateMe(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateProfileRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
 	}
 
-	user, err := h.authUsecase.GetMe(c.Context(), userID)
+	user, err := h.authUsecase.UpdateProfile(requestContext(c), userID, req.Name)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		return err
 	}
 
 	return c.JSON(user)
 }
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required,max=1024"`
+}
+
+func (h *AuthHandler) DeleteMe(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req DeleteAccountRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.authUsecase.DeleteAccount(requestContext(c), userID, req.Password); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=1024"`
+	NewPassword     string `json:"new_password" validate:"required,max=1024"`
+}
+
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req ChangePasswordRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.authUsecase.ChangePassword(requestContext(c), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// readinessTimeout bounds each check so a hanging dependency fails the probe
+// instead of outlasting the orchestrator's probe timeout.
+const readinessTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	checks []domain.ReadinessCheck
+}
+
+func NewHealthHandler(checks []domain.ReadinessCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Healthz reports that the process is up; it checks no dependencies.
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readyz runs every readiness check concurrently and answers 503 if any fails.
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	status := domain.ReadinessStatus{Ready: true, Checks: make(map[string]string, len(h.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check domain.ReadinessCheck) {
+			defer wg.Done()
+			// The probe is unauthenticated, so failures are only detailed in
+			// the log.
+			result := "ok"
+			if err := check.Check(ctx); err != nil {
+				slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", err)
+				result = "unavailable"
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			status.Checks[check.Name] = result
+			if result != "ok" {
+				status.Ready = false
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	if !status.Ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(status)
+	}
+	return c.JSON(status)
+}
@@ -0,0 +1,50 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	deliveryhttp "go-auth-service/internal/delivery/http"
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadyz(t *testing.T) {
+	readyz := func(t *testing.T, checks ...domain.ReadinessCheck) (int, domain.ReadinessStatus) {
+		app := fiber.New()
+		deliveryhttp.RegisterHealthRoutes(app, checks)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+		require.NoError(t, err)
+		var status domain.ReadinessStatus
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return resp.StatusCode, status
+	}
+	ok := domain.ReadinessCheck{Name: "database", Check: func(ctx context.Context) error { return nil }}
+
+	t.Run("Ready", func(t *testing.T) {
+		code, status := readyz(t, ok)
+
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.True(t, status.Ready)
+		assert.Equal(t, map[string]string{"database": "ok"}, status.Checks)
+	})
+
+	t.Run("HidesFailureDetail", func(t *testing.T) {
+		failing := domain.ReadinessCheck{Name: "redis", Check: func(ctx context.Context) error {
+			return errors.New("dial tcp 10.0.0.5:6379: connect: connection refused")
+		}}
+
+		code, status := readyz(t, ok, failing)
+
+		assert.Equal(t, fiber.StatusServiceUnavailable, code)
+		assert.False(t, status.Ready)
+		assert.Equal(t, map[string]string{"database": "ok", "redis": "unavailable"}, status.Checks)
+	})
+}
@@ -2,57 +2,169 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
-	constant "go-auth-service/internal/constants"
 	"go-auth-service/internal/domain"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrMissingAuthorization = errors.New("missing authorization header")
+	ErrInvalidAuthorization = errors.New("invalid authorization header format")
+	ErrInsufficientRole     = errors.New("insufficient permissions")
 )
 
 type AuthMiddleware struct {
 	tokenManager domain.TokenManager
-	redisClient  *redis.Client
+	revocations  domain.RevocationStore
+	users        domain.UserRepository
+	metrics      domain.Metrics
 }
 
-func NewAuthMiddleware(tokenManager domain.TokenManager, redisClient *redis.Client) *AuthMiddleware {
+// NewAuthMiddleware returns the middleware. Tokens of an ended session are
+// rejected through revocations, to which the session repository announces
+// them, so that revoking a session takes effect from the revocation cache
+// before its access tokens expire. Tokens without a session are checked
+// against the account in users on every request instead. users and metrics
+// may be nil.
+func NewAuthMiddleware(tokenManager domain.TokenManager, revocations domain.RevocationStore, users domain.UserRepository, metrics domain.Metrics) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenManager: tokenManager,
-		redisClient:  redisClient,
+		revocations:  revocations,
+		users:        users,
+		metrics:      metrics,
 	}
 }
 
+// Protected authenticates the request with its bearer token. Accounts that
+// must change their password are refused with ErrPasswordChangeRequired.
 func (m *AuthMiddleware) Protected() fiber.Handler {
+	return m.protected(true)
+}
+
+// ProtectedForPasswordChange is Protected for the routes an account that must
+// change its password needs to do so: reading its profile, changing the
+// password and logging out.
+func (m *AuthMiddleware) ProtectedForPasswordChange() fiber.Handler {
+	return m.protected(false)
+}
+
+func (m *AuthMiddleware) protected(enforcePasswordChange bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing authorization header"})
+			return ErrMissingAuthorization
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid authorization header format"})
+			return ErrInvalidAuthorization
 		}
 
 		tokenString := parts[1]
 
 		// Check blacklist
-		if m.redisClient != nil {
-			val, _ := m.redisClient.Get(context.Background(), constant.STR_BLACKLIST+tokenString).Result()
-			if val != "" {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token is blacklisted"})
-			}
+		if revoked, err := m.isRevoked(c.UserContext(), tokenString); err != nil {
+			return err
+		} else if revoked {
+			return domain.ErrTokenRevoked
 		}
 
+		start := time.Now()
 		claims, err := m.tokenManager.ValidateToken(tokenString, false)
+		if m.metrics != nil {
+			m.metrics.ObserveTokenValidation(false, time.Since(start), err)
+		}
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+			return err
 		}
 
+		if claims.SessionID != "" {
+			if revoked, err := m.isRevoked(c.UserContext(), domain.SessionRevocationKey(claims.SessionID)); err != nil {
+				return err
+			} else if revoked {
+				return domain.ErrSessionRevoked
+			}
+		}
+
+		role, err := m.checkAccount(c.UserContext(), claims, enforcePasswordChange)
+		if err != nil {
+			return err
+		}
+
+		c.SetUserContext(domain.ContextWithUserID(c.UserContext(), claims.UserID))
 		c.Locals("userID", claims.UserID)
+		c.Locals("role", role)
 		c.Locals("accessToken", tokenString) // Store for logout
 
 		return c.Next()
 	}
 }
+
+// isRevoked looks key up in the revocation store, which answers from its
+// cache when it has one.
+func (m *AuthMiddleware) isRevoked(ctx context.Context, key string) (bool, error) {
+	if m.revocations == nil {
+		return false, nil
+	}
+
+	revoked, err := m.revocations.IsRevoked(ctx, key)
+	if m.metrics != nil {
+		m.metrics.RevocationCheck(domain.RevocationCheckMiddleware, revoked, err)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", domain.ErrRevocationUnavailable, err)
+	}
+	return revoked, nil
+}
+
+// checkAccount applies changes made to the account after the token was
+// issued and returns the role to check. Disabling, deleting or demoting an
+// account ends its sessions, so the claims of a token from a live session
+// still hold; the account is only loaded when the token claims that the
+// password must be changed, to let it through once it has been. Tokens
+// without a session are checked against the account on every request, and
+// their claims are trusted only without users.
+func (m *AuthMiddleware) checkAccount(ctx context.Context, claims *domain.TokenClaims, enforcePasswordChange bool) (string, error) {
+	mustChangePassword := enforcePasswordChange && claims.MustChangePassword
+	if claims.SessionID != "" && !mustChangePassword {
+		return claims.Role, nil
+	}
+	if m.users == nil {
+		if mustChangePassword {
+			return "", domain.ErrPasswordChangeRequired
+		}
+		return claims.Role, nil
+	}
+
+	user, err := m.users.GetByID(ctx, claims.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return "", fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.DisabledAt != nil {
+		return "", domain.ErrAccountDisabled
+	}
+	if enforcePasswordChange && user.MustChangePassword {
+		return "", domain.ErrPasswordChangeRequired
+	}
+	return user.Role, nil
+}
+
+// RequireRole must run after Protected, which only sets current roles: a
+// role change ends the sessions whose tokens carry the old one.
+func (m *AuthMiddleware) RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userRole, _ := c.Locals("role").(string); userRole != role {
+			return ErrInsufficientRole
+		}
+
+		return c.Next()
+	}
+}
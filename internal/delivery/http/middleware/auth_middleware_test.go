@@ -0,0 +1,167 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	deliveryhttp "go-auth-service/internal/delivery/http"
+	"go-auth-service/internal/delivery/http/middleware"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+	"go-auth-service/internal/usecase"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := config.Config{
+		DBDriver:         "sqlite",
+		DBPath:           ":memory:",
+		JWTSecret:        "0123456789abcdef0123456789abcdef",
+		JWTRefreshSecret: "fedcba9876543210fedcba9876543210",
+		JWTAccessExpiry:  time.Minute,
+		JWTRefreshExpiry: time.Hour,
+	}
+	db, err := infrastructure.NewDatabase(cfg)
+	require.NoError(t, err)
+	users := repository.NewUserRepository(db)
+	tokens := service.NewTokenService(cfg, nil)
+
+	// The API instance answers from its revocation cache, while the operator
+	// acts through a second client that announces its revocations, as
+	// authctl does.
+	mr := miniredis.RunT(t)
+	newCache := func() *service.RevocationCache {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		return service.NewRevocationCache(repository.NewRedisRevocationStore(client, ""), client, "", "revocations", 100)
+	}
+	revocations := newCache()
+	go revocations.Run(ctx)
+	require.NoError(t, revocations.Revoke(ctx, "probe", time.Now().Add(time.Hour)))
+	mr.FlushAll()
+	require.Eventually(t, func() bool {
+		revoked, err := revocations.IsRevoked(ctx, "probe")
+		return err == nil && revoked
+	}, 5*time.Second, 10*time.Millisecond, "the cache answers without Redis")
+
+	sessions := service.NewRevokingSessionRepository(repository.NewSessionRepository(db), tokens, newCache(), cfg.JWTAccessExpiry)
+	admin := usecase.NewUserAdminUsecase(users, nil, sessions, nil, nil)
+
+	auth := middleware.NewAuthMiddleware(tokens, revocations, users, nil)
+	noContent := func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: deliveryhttp.ErrorHandler})
+	app.Get("/", auth.Protected(), noContent)
+	app.Put("/me/password", auth.ProtectedForPasswordChange(), noContent)
+	app.Get("/admin", auth.Protected(), auth.RequireRole(domain.RoleAdmin), noContent)
+
+	// login creates a user with a session and returns its access token.
+	login := func(t *testing.T, user *domain.User) (*domain.Session, string) {
+		user.Password = "hash"
+		if user.Role == "" {
+			user.Role = domain.RoleUser
+		}
+		require.NoError(t, users.Create(ctx, user))
+		session := &domain.Session{ID: user.Email, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, sessions.Create(ctx, session, "refresh-"+user.Email))
+		token, err := tokens.GenerateAccessToken(user, session.ID)
+		require.NoError(t, err)
+		return session, token
+	}
+	request := func(t *testing.T, method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	status := func(t *testing.T, token string) int {
+		return request(t, fiber.MethodGet, "/", token)
+	}
+	// rejected waits for the announcement to reach the cache of the API.
+	rejected := func(t *testing.T, path, token string) {
+		require.Eventually(t, func() bool {
+			return request(t, fiber.MethodGet, path, token) == fiber.StatusUnauthorized
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		_, token := login(t, &domain.User{Email: "valid@example.com"})
+		assert.Equal(t, fiber.StatusNoContent, status(t, token))
+	})
+
+	t.Run("DisabledAccount", func(t *testing.T) {
+		user := &domain.User{Email: "disabled@example.com"}
+		_, token := login(t, user)
+		require.NoError(t, admin.SetDisabled(ctx, user.ID, true))
+
+		rejected(t, "/", token)
+		mr.FlushAll()
+		assert.Equal(t, fiber.StatusUnauthorized, status(t, token), "answered from the cache")
+	})
+
+	t.Run("DeletedAccount", func(t *testing.T) {
+		user := &domain.User{Email: "deleted@example.com"}
+		_, token := login(t, user)
+		require.NoError(t, users.Delete(ctx, user.ID))
+		_, err := sessions.DeleteByUser(ctx, user.ID)
+		require.NoError(t, err)
+
+		rejected(t, "/", token)
+	})
+
+	t.Run("RevokedSession", func(t *testing.T) {
+		session, token := login(t, &domain.User{Email: "revoked@example.com"})
+		require.NoError(t, admin.RevokeSession(ctx, session.ID))
+
+		rejected(t, "/", token)
+	})
+
+	t.Run("PasswordChangeRequired", func(t *testing.T) {
+		user := &domain.User{Email: "reset@example.com", MustChangePassword: true}
+		_, token := login(t, user)
+
+		assert.Equal(t, fiber.StatusForbidden, status(t, token))
+		assert.Equal(t, fiber.StatusNoContent, request(t, fiber.MethodPut, "/me/password", token), "the password can still be changed")
+
+		require.NoError(t, users.UpdatePassword(ctx, user.ID, "new-hash"))
+		assert.Equal(t, fiber.StatusNoContent, status(t, token))
+	})
+
+	t.Run("RoleChange", func(t *testing.T) {
+		user := &domain.User{Email: "demoted@example.com", Role: domain.RoleAdmin}
+		_, token := login(t, user)
+		assert.Equal(t, fiber.StatusNoContent, request(t, fiber.MethodGet, "/admin", token))
+
+		require.NoError(t, admin.SetRole(ctx, user.ID, domain.RoleUser))
+
+		rejected(t, "/admin", token)
+	})
+
+	t.Run("WithoutSession", func(t *testing.T) {
+		user := &domain.User{Email: "legacy@example.com"}
+		login(t, user)
+		token, err := tokens.GenerateAccessToken(user, "")
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, status(t, token))
+
+		// Nothing announces the end of a token without a session, so its
+		// account is looked up instead.
+		now := time.Now()
+		require.NoError(t, users.SetDisabled(ctx, user.ID, &now))
+		assert.Equal(t, fiber.StatusForbidden, status(t, token))
+	})
+}
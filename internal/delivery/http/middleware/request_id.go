@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength keeps a client-supplied ID from bloating every log line.
+const maxRequestIDLength = 128
+
+// RequestID adopts the caller's X-Request-ID if it is a plain token, or
+// generates one, echoes it in the response and puts it in c.UserContext().
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if validRequestID(id) {
+			id = utils.CopyString(id)
+		} else {
+			id = uuid.NewString()
+		}
+
+		c.Set(HeaderRequestID, id)
+		c.Locals("requestID", id)
+		c.SetUserContext(domain.ContextWithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// validRequestID rejects IDs that could forge or break log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':', r == '/', r == '+', r == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
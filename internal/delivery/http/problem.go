@@ -0,0 +1,123 @@
+package http
+
+import (
+	"errors"
+	"strings"
+
+	"go-auth-service/internal/delivery/http/middleware"
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// problemTypePrefix namespaces the problem type URIs; the code is appended.
+const problemTypePrefix = "urn:go-auth-service:problem:"
+
+var errInvalidBody = errors.New("invalid request body")
+
+// Problem is an RFC 7807 problem details body. Code is stable and meant for
+// clients to switch on; Title and Detail are for humans and may change.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Errors            []domain.FieldError      `json:"errors,omitempty"`
+	Violations        []domain.PolicyViolation `json:"violations,omitempty"`
+	ChallengeRequired bool                     `json:"challenge_required,omitempty"`
+}
+
+type problemType struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// problemTypes maps the errors clients can act on. The detail of these
+// problems is the error's own message, never the message of a wrapped cause.
+var problemTypes = []problemType{
+	{errInvalidBody, fiber.StatusBadRequest, "invalid_body", "Invalid request body"},
+	{middleware.ErrMissingAuthorization, fiber.StatusUnauthorized, "missing_authorization", "Missing authorization"},
+	{middleware.ErrInvalidAuthorization, fiber.StatusUnauthorized, "invalid_authorization", "Invalid authorization header"},
+	{middleware.ErrInsufficientRole, fiber.StatusForbidden, "insufficient_permissions", "Insufficient permissions"},
+	{domain.ErrInvalidCredentials, fiber.StatusUnauthorized, "invalid_credentials", "Invalid credentials"},
+	{domain.ErrInvalidToken, fiber.StatusUnauthorized, "invalid_token", "Invalid token"},
+	{domain.ErrTokenRevoked, fiber.StatusUnauthorized, "token_revoked", "Token revoked"},
+	{domain.ErrSessionRevoked, fiber.StatusUnauthorized, "session_revoked", "Session revoked"},
+	{domain.ErrChallengeRequired, fiber.StatusUnauthorized, "challenge_required", "Verification required"},
+	{domain.ErrAccountDisabled, fiber.StatusForbidden, "account_disabled", "Account disabled"},
+	{domain.ErrPasswordChangeRequired, fiber.StatusForbidden, "password_change_required", "Password change required"},
+	{domain.ErrUserNotFound, fiber.StatusNotFound, "user_not_found", "User not found"},
+	{domain.ErrWebhookNotFound, fiber.StatusNotFound, "webhook_not_found", "Webhook not found"},
+	{domain.ErrDeliveryNotFound, fiber.StatusNotFound, "delivery_not_found", "Webhook delivery not found"},
+	{domain.ErrEmailTaken, fiber.StatusConflict, "email_taken", "Email already registered"},
+	{domain.ErrDeliveryNotRetryable, fiber.StatusConflict, "delivery_not_retryable", "Delivery not retryable"},
+	{domain.ErrLoginBlocked, fiber.StatusTooManyRequests, "login_blocked", "Too many login attempts"},
+	{domain.ErrRevocationUnavailable, fiber.StatusServiceUnavailable, "revocation_unavailable", "Unable to verify token, try again later"},
+}
+
+// ErrorHandler is the Fiber error handler: it renders every error returned by
+// a handler or middleware as problem details. Errors it does not know become
+// a 500 whose detail is withheld; RequestLogger logs the original error.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	problem := NewProblem(err)
+	problem.Instance = c.Path()
+	problem.RequestID, _ = c.Locals("requestID").(string)
+
+	return c.Status(problem.Status).JSON(problem, MIMEProblemJSON)
+}
+
+// NewProblem maps err to its problem details, without the request fields.
+func NewProblem(err error) Problem {
+	var (
+		validationErr *domain.ValidationError
+		policyErr     *domain.PasswordPolicyError
+		fiberErr      *fiber.Error
+	)
+	switch {
+	case errors.As(err, &validationErr):
+		p := newProblem(fiber.StatusBadRequest, "validation_failed", "Invalid request", "one or more fields are invalid")
+		p.Errors = validationErr.Fields
+		return p
+	case errors.As(err, &policyErr):
+		p := newProblem(fiber.StatusUnprocessableEntity, "password_policy", "Password does not meet the password policy", policyErr.Error())
+		p.Violations = policyErr.Violations
+		return p
+	}
+
+	for _, t := range problemTypes {
+		if errors.Is(err, t.err) {
+			p := newProblem(t.status, t.code, t.title, t.err.Error())
+			p.ChallengeRequired = t.err == domain.ErrChallengeRequired
+			return p
+		}
+	}
+
+	// Fiber's own errors (unknown route, body too large...) carry safe messages.
+	if errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+		title := utils.StatusMessage(fiberErr.Code)
+		code := strings.ToLower(strings.ReplaceAll(title, " ", "_"))
+		return newProblem(fiberErr.Code, code, title, fiberErr.Message)
+	}
+
+	return newProblem(fiber.StatusInternalServerError, "internal_error", "Internal server error", "")
+}
+
+func newProblem(status int, code, title, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
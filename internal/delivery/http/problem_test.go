@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	deliveryhttp "go-auth-service/internal/delivery/http"
+	"go-auth-service/internal/delivery/http/middleware"
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	serve := func(t *testing.T, err error) (int, string, deliveryhttp.Problem) {
+		app := fiber.New(fiber.Config{ErrorHandler: deliveryhttp.ErrorHandler})
+		app.Use(middleware.RequestID(), middleware.RequestLogger())
+		app.Get("/fail", func(c *fiber.Ctx) error { return err })
+
+		req := httptest.NewRequest(fiber.MethodGet, "/fail", nil)
+		req.Header.Set(middleware.HeaderRequestID, "req-1")
+		resp, testErr := app.Test(req)
+		require.NoError(t, testErr)
+		body, _ := io.ReadAll(resp.Body)
+
+		var problem deliveryhttp.Problem
+		require.NoError(t, json.Unmarshal(body, &problem))
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), problem
+	}
+
+	t.Run("DomainError", func(t *testing.T) {
+		status, contentType, problem := serve(t, fmt.Errorf("login: %w", domain.ErrInvalidCredentials))
+
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		assert.Equal(t, deliveryhttp.MIMEProblemJSON, contentType)
+		assert.Equal(t, "invalid_credentials", problem.Code)
+		assert.Equal(t, "invalid credentials", problem.Detail)
+		assert.Equal(t, "/fail", problem.Instance)
+		assert.Equal(t, "req-1", problem.RequestID)
+	})
+
+	t.Run("WrappedCauseIsHidden", func(t *testing.T) {
+		_, _, problem := serve(t, fmt.Errorf("%w: %w", domain.ErrInvalidToken, errors.New("signature is invalid")))
+
+		assert.Equal(t, "invalid_token", problem.Code)
+		assert.Equal(t, "invalid or expired token", problem.Detail)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		status, _, problem := serve(t, domain.NewValidationError("email", "email", "must be a valid email address"))
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, "validation_failed", problem.Code)
+		assert.Equal(t, []domain.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}, problem.Errors)
+	})
+
+	t.Run("PasswordPolicy", func(t *testing.T) {
+		status, _, problem := serve(t, &domain.PasswordPolicyError{Violations: []domain.PolicyViolation{{Rule: "min_length", Message: "too short"}}})
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+		assert.Equal(t, "password_policy", problem.Code)
+		assert.Len(t, problem.Violations, 1)
+	})
+
+	t.Run("FiberError", func(t *testing.T) {
+		status, _, problem := serve(t, fiber.ErrRequestEntityTooLarge)
+
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+		assert.Equal(t, "request_entity_too_large", problem.Code)
+	})
+
+	t.Run("InternalErrorIsHidden", func(t *testing.T) {
+		status, _, problem := serve(t, errors.New("pq: connection refused to 10.0.0.5"))
+
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+		assert.Equal(t, "internal_error", problem.Code)
+		assert.Empty(t, problem.Detail)
+	})
+}
@@ -1,20 +1,56 @@
 package http
 
 import (
+	nethttp "net/http"
+
 	"go-auth-service/internal/delivery/http/middleware"
 	"go-auth-service/internal/domain"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
 func RegisterUserRoutes(app *fiber.App, authUsecase domain.AuthUsecase, authMiddleware *middleware.AuthMiddleware) {
 	handler := NewAuthHandler(authUsecase)
 
 	auth := app.Group("/auth")
-	auth.Post("/register", handler.Register)
-	auth.Post("/login", handler.Login)
-	auth.Post("/refresh", handler.Refresh)
-	auth.Post("/logout", authMiddleware.Protected(), handler.Logout)
+	auth.Post("/register", middleware.Span("AuthHandler.Register", handler.Register))
+	auth.Post("/login", middleware.Span("AuthHandler.Login", handler.Login))
+	auth.Post("/refresh", middleware.Span("AuthHandler.Refresh", handler.Refresh))
+	auth.Post("/logout", authMiddleware.ProtectedForPasswordChange(), middleware.Span("AuthHandler.Logout", handler.Logout))
+
+	app.Get("/me", authMiddleware.ProtectedForPasswordChange(), middleware.Span("AuthHandler.GetMe", handler.GetMe))
+	app.Patch("/me", authMiddleware.Protected(), middleware.Span("AuthHandler.UpdateMe", handler.UpdateMe))
+	app.Delete("/me", authMiddleware.Protected(), middleware.Span("AuthHandler.DeleteMe", handler.DeleteMe))
+	app.Put("/me/password", authMiddleware.ProtectedForPasswordChange(), middleware.Span("AuthHandler.ChangePassword", handler.ChangePassword))
+}
+
+func RegisterAdminRoutes(app *fiber.App, auditUsecase domain.AuditUsecase, auditLogger domain.AuditLogger, webhookUsecase domain.WebhookUsecase, revocationHealth domain.RevocationHealth, authMiddleware *middleware.AuthMiddleware) {
+	handler := NewAdminHandler(auditUsecase, auditLogger, webhookUsecase, revocationHealth)
+
+	admin := app.Group("/admin", authMiddleware.Protected(), authMiddleware.RequireRole(domain.RoleAdmin))
+	admin.Get("/audit", handler.ListAudit)
+
+	admin.Post("/webhooks", handler.CreateWebhook)
+	admin.Get("/webhooks", handler.ListWebhooks)
+	admin.Delete("/webhooks/:id", handler.DeleteWebhook)
+	admin.Get("/webhooks/deliveries", handler.ListWebhookDeliveries)
+	admin.Post("/webhooks/deliveries/:id/retry", handler.RetryWebhookDelivery)
+
+	admin.Get("/status/revocation", handler.RevocationStatus)
+}
+
+// RegisterHealthRoutes adds the unauthenticated probes for the orchestrator.
+func RegisterHealthRoutes(app *fiber.App, checks []domain.ReadinessCheck) {
+	handler := NewHealthHandler(checks)
+
+	app.Get("/healthz", handler.Healthz)
+	app.Get("/readyz", handler.Readyz)
+}
 
-	app.Get("/me", authMiddleware.Protected(), handler.GetMe)
+// RegisterMetricsRoutes serves the Prometheus metrics without authentication,
+// on an app of their own that listens on METRICS_PORT rather than the
+// public port.
+func RegisterMetricsRoutes(app *fiber.App, handler nethttp.Handler) {
+	app.Get("/metrics", adaptor.HTTPHandler(handler))
 }
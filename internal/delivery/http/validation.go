@@ -0,0 +1,104 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report fields by their JSON name, as the client sent them.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	if err := v.RegisterValidation("personname", validPersonName); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// validPersonName accepts letters in any script, combining marks, spaces
+// and the punctuation common in names: apostrophes, hyphens and periods.
+func validPersonName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if strings.TrimSpace(name) != name {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.Is(unicode.M, r):
+		case r == ' ', r == '\'', r == '’', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseBody decodes the request body into req and validates it against its
+// validate tags, before anything reaches the usecase.
+func parseBody(c *fiber.Ctx, req any) error {
+	if err := c.BodyParser(req); err != nil {
+		return errInvalidBody
+	}
+	return validateStruct(req)
+}
+
+func validateStruct(req any) error {
+	err := validate.Struct(req)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	fields := make([]domain.FieldError, len(fieldErrs))
+	for i, fe := range fieldErrs {
+		// The namespace starts with the request type: RegisterRequest.email.
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		fields[i] = domain.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		}
+	}
+	return &domain.ValidationError{Fields: fields}
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := "characters"
+	if k := fe.Kind(); k == reflect.Slice || k == reflect.Array {
+		unit = "items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "jwt":
+		return "must be a JWT"
+	case "http_url":
+		return "must be an absolute http(s) URL"
+	case "personname":
+		return "may only contain letters, spaces, apostrophes, hyphens and periods"
+	case "min":
+		return fmt.Sprintf("must be at least %s %s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s %s", fe.Param(), unit)
+	}
+	return "is invalid"
+}
@@ -0,0 +1,134 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	deliveryhttp "go-auth-service/internal/delivery/http"
+	"go-auth-service/internal/delivery/http/middleware"
+	"go-auth-service/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingUsecase records registrations, logins and logouts, and fails the
+// test through a nil-interface panic if any other request reaches it.
+type recordingUsecase struct {
+	domain.AuthUsecase
+	registered []*domain.User
+	logins     []string
+	logouts    []string
+}
+
+func (u *recordingUsecase) Register(_ context.Context, user *domain.User) error {
+	u.registered = append(u.registered, user)
+	return nil
+}
+
+func (u *recordingUsecase) Login(_ context.Context, email, password string) (string, string, error) {
+	u.logins = append(u.logins, email)
+	return "", "", domain.ErrInvalidCredentials
+}
+
+func (u *recordingUsecase) Logout(_ context.Context, accessToken, refreshToken string) error {
+	u.logouts = append(u.logouts, refreshToken)
+	return nil
+}
+
+// acceptingTokenManager accepts every access token.
+type acceptingTokenManager struct {
+	domain.TokenManager
+}
+
+func (acceptingTokenManager) ValidateToken(token string, isRefresh bool) (*domain.TokenClaims, error) {
+	return &domain.TokenClaims{UserID: 1, Role: domain.RoleUser}, nil
+}
+
+func TestRequestValidation(t *testing.T) {
+	usecase := &recordingUsecase{}
+	app := fiber.New(fiber.Config{ErrorHandler: deliveryhttp.ErrorHandler})
+	deliveryhttp.RegisterUserRoutes(app, usecase, middleware.NewAuthMiddleware(acceptingTokenManager{}, nil, nil, nil))
+
+	post := func(t *testing.T, path, body string) (int, deliveryhttp.Problem) {
+		req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("Authorization", "Bearer access-token")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var problem deliveryhttp.Problem
+		_ = json.NewDecoder(resp.Body).Decode(&problem)
+		return resp.StatusCode, problem
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		status, _ := post(t, "/auth/register", `{"email":"anne@example.com","password":"violet-ferry-lantern-92","name":"Anne-Marie O'Neil"}`)
+
+		assert.Equal(t, fiber.StatusCreated, status)
+		require.Len(t, usecase.registered, 1)
+		assert.Equal(t, "anne@example.com", usecase.registered[0].Email)
+	})
+
+	t.Run("FieldErrors", func(t *testing.T) {
+		status, problem := post(t, "/auth/register", `{"email":"not-an-email","name":"<script>"}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, "validation_failed", problem.Code)
+		assert.Equal(t, []domain.FieldError{
+			{Field: "email", Rule: "email", Message: "must be a valid email address"},
+			{Field: "password", Rule: "required", Message: "is required"},
+			{Field: "name", Rule: "personname", Message: "may only contain letters, spaces, apostrophes, hyphens and periods"},
+		}, problem.Errors)
+	})
+
+	t.Run("Lengths", func(t *testing.T) {
+		email := strings.Repeat("a", 250) + "@example.com"
+		_, problem := post(t, "/auth/login", `{"email":"`+email+`","password":"x"}`)
+
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "email", problem.Errors[0].Field)
+		assert.Equal(t, "must be at most 254 characters", problem.Errors[0].Message)
+	})
+
+	t.Run("TokenFormat", func(t *testing.T) {
+		status, problem := post(t, "/auth/refresh", `{"refresh_token":"not a token"}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, []domain.FieldError{{Field: "refresh_token", Rule: "jwt", Message: "must be a JWT"}}, problem.Errors)
+	})
+
+	t.Run("LoginEmailFormatIsNotChecked", func(t *testing.T) {
+		status, problem := post(t, "/auth/login", `{"email":"legacy-user","password":"x"}`)
+
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+		assert.Equal(t, "invalid_credentials", problem.Code)
+		assert.Equal(t, []string{"legacy-user"}, usecase.logins)
+	})
+
+	t.Run("LogoutRefreshTokenIsOptional", func(t *testing.T) {
+		for _, body := range []string{"", `{}`} {
+			status, _ := post(t, "/auth/logout", body)
+			assert.Equal(t, fiber.StatusOK, status, body)
+		}
+		assert.Equal(t, []string{"", ""}, usecase.logouts)
+
+		status, problem := post(t, "/auth/logout", `{"refresh_token":"not a token"}`)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, []domain.FieldError{{Field: "refresh_token", Rule: "jwt", Message: "must be a JWT"}}, problem.Errors)
+		assert.Len(t, usecase.logouts, 2)
+	})
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		status, problem := post(t, "/auth/login", `{"email":`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, "invalid_body", problem.Code)
+	})
+
+	assert.Len(t, usecase.registered, 1, "invalid requests never reach the usecase")
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	AuditActionRegister       = "user.register"
+	AuditActionLogin          = "auth.login"
+	AuditActionLoginThreat    = "auth.login_threat"
+	AuditActionRefresh        = "auth.refresh"
+	AuditActionRefreshReuse   = "auth.refresh_reuse"
+	AuditActionLogout         = "auth.logout"
+	AuditActionProfileUpdate  = "user.profile_update"
+	AuditActionAccountDelete  = "user.delete"
+	AuditActionPasswordChange = "user.password_change"
+	AuditActionPasswordBreach = "user.password_breached"
+	AuditActionAuditQuery     = "admin.audit_query"
+	AuditActionUserCreate     = "admin.user_create"
+	AuditActionPasswordSet    = "admin.password_set"
+	AuditActionUserDisable    = "admin.user_disable"
+	AuditActionUserEnable     = "admin.user_enable"
+	AuditActionRoleChange     = "admin.role_change"
+	AuditActionSessionRevoke  = "admin.session_revoke"
+	AuditActionKeyRotate      = "admin.key_rotate"
+	AuditActionKeyRetire      = "admin.key_retire"
+	AuditActionReencrypt      = "admin.secrets_reencrypt"
+
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeFlagged = "flagged"
+)
+
+// AuditEvent is a single, immutable record of an authentication-relevant action.
+// Events form a hash chain: Seq is contiguous and each Hash covers the event's
+// fields plus the previous event's Hash, so edits, deletions and reordering
+// are detectable. Events written before chaining was introduced keep Seq 0 and
+// are not part of the chain.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Seq       uint64    `gorm:"index;not null;default:0" json:"seq"`
+	CreatedAt time.Time `gorm:"index;not null" json:"created_at"`
+	Action    string    `gorm:"size:64;index;not null" json:"action"`
+	Outcome   string    `gorm:"size:16;not null" json:"outcome"`
+	UserID    *uint     `gorm:"index" json:"user_id,omitempty"`
+	Email     string    `gorm:"size:255;index" json:"email,omitempty"`
+	IP        string    `gorm:"size:64" json:"ip,omitempty"`
+	UserAgent string    `gorm:"size:512" json:"user_agent,omitempty"`
+	Reason    string    `gorm:"size:255" json:"reason,omitempty"`
+	PrevHash  string    `gorm:"size:64;not null;default:''" json:"prev_hash"`
+	Hash      string    `gorm:"size:64;not null;default:''" json:"hash"`
+}
+
+// ComputeHash returns the chain hash of the event. Every field is length
+// prefixed so that values cannot be shifted between fields.
+func (e *AuditEvent) ComputeHash() string {
+	userID := ""
+	if e.UserID != nil {
+		userID = strconv.FormatUint(uint64(*e.UserID), 10)
+	}
+
+	h := sha256.New()
+	for _, field := range []string{
+		strconv.FormatUint(e.Seq, 10),
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.Action,
+		e.Outcome,
+		userID,
+		e.Email,
+		e.IP,
+		e.UserAgent,
+		e.Reason,
+	} {
+		fmt.Fprintf(h, "%d:%s|", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AuditCheckpoint is a signed statement of the chain head at EventSeq. It
+// lets a verifier detect truncation of the chain's tail and a full rewrite of
+// the chain by someone without the signing key. Checkpoints are written every
+// AUDIT_CHECKPOINT_INTERVAL events and whenever the head is signed on a timer
+// or at shutdown, so only events after the latest one can be cut unnoticed.
+type AuditCheckpoint struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	EventSeq  uint64    `gorm:"uniqueIndex;not null" json:"event_seq"`
+	EventHash string    `gorm:"size:64;not null" json:"event_hash"`
+	Signature string    `gorm:"size:128;not null" json:"signature"`
+}
+
+func (c *AuditCheckpoint) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("audit-checkpoint|%d|%s", c.EventSeq, c.EventHash))
+}
+
+type CheckpointSigner interface {
+	Sign(payload []byte) (string, error)
+	Verify(payload []byte, signature string) error
+}
+
+type AuditFilter struct {
+	UserID   *uint
+	Action   string
+	Outcome  string
+	Email    string
+	IP       string
+	From     time.Time
+	To       time.Time
+	Page     int
+	PageSize int
+}
+
+// AuditRepository only appends and reads; audit records are never updated or deleted.
+type AuditRepository interface {
+	Append(ctx context.Context, event *AuditEvent) error
+	List(ctx context.Context, filter AuditFilter) ([]AuditEvent, int64, error)
+	// ListChain returns up to limit events with Seq > afterSeq in chain order.
+	ListChain(ctx context.Context, afterSeq uint64, limit int) ([]AuditEvent, error)
+	ListCheckpoints(ctx context.Context) ([]AuditCheckpoint, error)
+	// SignHead writes a checkpoint of the current chain head. It returns nil
+	// if the chain is empty or its head already has a checkpoint.
+	SignHead(ctx context.Context) (*AuditCheckpoint, error)
+}
+
+type AuditLogger interface {
+	Log(ctx context.Context, event *AuditEvent) error
+}
+
+type AuditUsecase interface {
+	List(ctx context.Context, filter AuditFilter) ([]AuditEvent, int64, error)
+}
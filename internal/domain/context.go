@@ -0,0 +1,47 @@
+package domain
+
+import "context"
+
+// ClientInfo describes the caller of a request as seen by the delivery layer.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	// ChallengeResponse answers the challenge asked of flagged login sources.
+	ChallengeResponse string
+}
+
+type clientInfoKey struct{}
+
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID tags everything done for a request, logs in
+// particular, with its ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type userIDKey struct{}
+
+// ContextWithUserID records the authenticated user of a request.
+func ContextWithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(userIDKey{}).(uint)
+	return id, ok
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoKeyProvider = errors.New("value is encrypted but no key provider is configured")
+
+// KeyProvider is a key management service: it issues data keys and unwraps
+// them again with key encryption keys that never leave it. Only wrapped data
+// keys are stored.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit data key and its wrapped form.
+	GenerateDataKey(ctx context.Context) (key, wrapped []byte, err error)
+	// UnwrapDataKey returns the data key a wrapped form was made from.
+	UnwrapDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// SecretCipher encrypts sensitive column values at rest, such as signing key
+// and webhook secrets. The aad passed to both methods binds a value to where
+// it is stored (see ColumnAAD), so a value copied into another row or column
+// does not decrypt.
+type SecretCipher interface {
+	Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error)
+	// Decrypt also accepts values stored before encryption was enabled and
+	// returns them unchanged.
+	Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error)
+}
+
+// ColumnAAD identifies a column of one row, as additional data for
+// SecretCipher.
+func ColumnAAD(table, column, rowID string) []byte {
+	return []byte(table + "." + column + ":" + rowID)
+}
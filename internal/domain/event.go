@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventUserRegistered      = "user.registered"
+	EventUserUpdated         = "user.updated"
+	EventUserPasswordChanged = "user.password_changed"
+	EventUserDeleted         = "user.deleted"
+	EventUserRoleChanged     = "user.role_changed"
+	EventUserDisabled        = "user.disabled"
+	EventUserEnabled         = "user.enabled"
+	EventLoginSourceFlagged  = "security.login_source_flagged"
+	EventRefreshTokenReuse   = "security.refresh_token_reuse"
+)
+
+// EventTypes lists every event type a consumer can subscribe to.
+var EventTypes = []string{
+	EventUserRegistered,
+	EventUserUpdated,
+	EventUserPasswordChanged,
+	EventUserDeleted,
+	EventUserRoleChanged,
+	EventUserDisabled,
+	EventUserEnabled,
+	EventLoginSourceFlagged,
+	EventRefreshTokenReuse,
+}
+
+// DomainEvent is a fact about a user or about security that other systems can react to.
+type DomainEvent struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	UserID     uint           `json:"user_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+func NewDomainEvent(eventType string, userID uint, data map[string]any) DomainEvent {
+	return DomainEvent{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		UserID:     userID,
+		Data:       data,
+	}
+}
+
+func NewUserEvent(eventType string, user *User) DomainEvent {
+	return NewDomainEvent(eventType, user.ID, map[string]any{
+		"email": user.Email,
+		"name":  user.Name,
+	})
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event DomainEvent) error
+}
+
+// EventSink is a destination the outbox relay forwards events to. Delivery is
+// at-least-once: sinks may see the same event more than once and consumers
+// should de-duplicate on DomainEvent.ID.
+type EventSink interface {
+	Name() string
+	Send(ctx context.Context, event DomainEvent) error
+}
+
+// OutboxEvent is a DomainEvent persisted in the same transaction as the state
+// change it describes. IdempotencyKey is the DomainEvent.ID. An event that
+// failed to relay is retried at NextAttemptAt, until it has used up its
+// attempts and is dead-lettered by setting DeadAt.
+type OutboxEvent struct {
+	ID             uint       `gorm:"primaryKey"`
+	IdempotencyKey string     `gorm:"size:64;uniqueIndex;not null"`
+	EventType      string     `gorm:"size:64;not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	CreatedAt      time.Time  `gorm:"not null"`
+	PublishedAt    *time.Time `gorm:"index"`
+	NextAttemptAt  time.Time  `gorm:"not null;index"`
+	DeadAt         *time.Time
+	Attempts       int    `gorm:"not null;default:0"`
+	LastError      string `gorm:"size:1024"`
+}
+
+func NewOutboxEvent(event DomainEvent) (*OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &OutboxEvent{
+		IdempotencyKey: event.ID,
+		EventType:      event.Type,
+		Payload:        string(payload),
+		CreatedAt:      now,
+		NextAttemptAt:  now,
+	}, nil
+}
+
+func (e *OutboxEvent) DomainEvent() (DomainEvent, error) {
+	var event DomainEvent
+	err := json.Unmarshal([]byte(e.Payload), &event)
+	return event, err
+}
+
+type OutboxRepository interface {
+	EventPublisher
+	// ClaimDueEvents leases up to limit due unpublished events, oldest first,
+	// by pushing their NextAttemptAt forward by lease, so other relays skip
+	// them. No lock is held once it returns.
+	ClaimDueEvents(ctx context.Context, limit int, lease time.Duration) ([]OutboxEvent, error)
+	// UpdateEvent stores the outcome of a relay attempt.
+	UpdateEvent(ctx context.Context, event *OutboxEvent) error
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+// ReadinessCheck is one dependency the service needs to serve requests.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type ReadinessStatus struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Label values of Metrics. Reasons passed to AuthOutcome are the audit
+// reasons, which come from a small fixed set.
+const (
+	FlowRegister = "register"
+	FlowLogin    = "login"
+	FlowRefresh  = "refresh"
+	FlowLogout   = "logout"
+
+	HashOperationHash   = "hash"
+	HashOperationVerify = "verify"
+
+	RevocationCheckMiddleware = "middleware"
+	RevocationCheckRefresh    = "refresh"
+
+	DependencyDatabase = "database"
+	DependencyRedis    = "redis"
+)
+
+// Metrics records operational measurements of the auth flows and the stores
+// behind them.
+type Metrics interface {
+	AuthOutcome(flow, outcome, reason string)
+	ObservePasswordHash(operation string, d time.Duration)
+	ObserveTokenValidation(refresh bool, d time.Duration, err error)
+	// RevocationCheck counts blacklist lookups; err is set if the store
+	// could not answer.
+	RevocationCheck(source string, revoked bool, err error)
+	DependencyError(dependency, operation string)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordHistoryEntry is a hash of a password the user has set before.
+type PasswordHistoryEntry struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index:idx_password_history_user_created,priority:1"`
+	Hash      string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"index:idx_password_history_user_created,priority:2"`
+}
+
+func (PasswordHistoryEntry) TableName() string {
+	return "password_history"
+}
+
+// PasswordHistoryRepository keeps the most recent password hashes per user;
+// how many and for how long is decided by the implementation.
+type PasswordHistoryRepository interface {
+	Append(ctx context.Context, userID uint, hash string) error
+	Recent(ctx context.Context, userID uint) ([]string, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// PolicyViolation is one failed password rule, in a shape the frontend can
+// render: a stable rule identifier, a human readable message and the rule's
+// parameters (e.g. {"min": 12}).
+type PolicyViolation struct {
+	Rule    string         `json:"rule"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+type PasswordPolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		rules[i] = v.Rule
+	}
+	return "password does not meet policy: " + strings.Join(rules, ", ")
+}
+
+// PasswordCandidate is a password being set together with what is known about
+// its owner, so rules can reject passwords derived from personal details.
+type PasswordCandidate struct {
+	Password string
+	UserID   uint
+	Email    string
+	Name     string
+}
+
+type PasswordRule interface {
+	Check(ctx context.Context, candidate PasswordCandidate) ([]PolicyViolation, error)
+}
+
+// PasswordPolicy returns a *PasswordPolicyError listing every violated rule,
+// or another error if a rule could not be evaluated.
+type PasswordPolicy interface {
+	Validate(ctx context.Context, candidate PasswordCandidate) error
+}
+
+// BreachedPasswordChecker reports whether a password appears in a corpus of
+// passwords known from public breaches.
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
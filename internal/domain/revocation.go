@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRevocationUnavailable is returned when the revocation status of a token
+// cannot be determined and the store is configured to fail closed.
+var ErrRevocationUnavailable = errors.New("token revocation status unavailable")
+
+// RevocationFailureMode decides what happens while the revocation store is
+// unreachable.
+type RevocationFailureMode string
+
+const (
+	// RevocationFailClosed rejects tokens whose status is unknown.
+	RevocationFailClosed RevocationFailureMode = "closed"
+	// RevocationFailOpen accepts them and drops revocations.
+	RevocationFailOpen RevocationFailureMode = "open"
+	// RevocationFailover reads and writes a secondary store instead.
+	RevocationFailover RevocationFailureMode = "fallback"
+)
+
+// RevocationStore remembers revoked tokens until they would have expired
+// anyway, after which they may be forgotten.
+type RevocationStore interface {
+	Revoke(ctx context.Context, token string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, token string) (bool, error)
+}
+
+// RevokedToken is a row of the Postgres revocation store. Only the SHA-256 of
+// the token is kept, so the table cannot be used to replay tokens.
+type RevokedToken struct {
+	TokenHash string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// RevocationStatus reports the health of the revocation store. Counters are
+// cumulative since start-up.
+type RevocationStatus struct {
+	Mode          RevocationFailureMode `json:"mode"`
+	Degraded      bool                  `json:"degraded"`
+	DegradedSince *time.Time            `json:"degraded_since,omitempty"`
+	LastError     string                `json:"last_error,omitempty"`
+	PrimaryErrors uint64                `json:"primary_errors"`
+	FallbackOps   uint64                `json:"fallback_ops"`
+	FailedOpen    uint64                `json:"failed_open"`
+	FailedClosed  uint64                `json:"failed_closed"`
+	// PendingReplays counts revocations made during an outage that the
+	// primary store has not received yet.
+	PendingReplays int `json:"pending_replays"`
+}
+
+type RevocationHealth interface {
+	Status() RevocationStatus
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrLoginBlocked      = errors.New("too many failed login attempts, try again later")
+	ErrChallengeRequired = errors.New("additional verification required")
+)
+
+type ThreatAction string
+
+const (
+	ThreatActionNone      ThreatAction = ""
+	ThreatActionAlert     ThreatAction = "alert"
+	ThreatActionChallenge ThreatAction = "challenge"
+	ThreatActionBlock     ThreatAction = "block"
+)
+
+// ThreatVerdict is the outcome of evaluating a login source. Scope and Source
+// identify what was flagged (e.g. "cidr" / "203.0.113.0/24").
+type ThreatVerdict struct {
+	Action       ThreatAction
+	Scope        string
+	Source       string
+	Reason       string
+	NewlyFlagged bool
+}
+
+type LoginAttempt struct {
+	Email    string
+	Password string
+	IP       string
+}
+
+// ChallengeVerifier checks the response to a challenge, such as a CAPTCHA,
+// that login sources flagged with ThreatActionChallenge must solve.
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, response, ip string) (bool, error)
+}
+
+// LoginThreatDetector aggregates failed logins across accounts to spot
+// credential stuffing and password spraying that per-account checks miss.
+type LoginThreatDetector interface {
+	Check(ctx context.Context, ip string) (ThreatVerdict, error)
+	RecordFailure(ctx context.Context, attempt LoginAttempt) (ThreatVerdict, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSessionRevoked is returned when a refresh token no longer belongs to a
+// session, because the session was logged out, revoked by an operator or
+// expired.
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// SessionRevocationKey is the revocation store entry that rejects the access
+// tokens of an ended session. It cannot collide with a token.
+func SessionRevocationKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// Session is a login, tracked by a hash of its current refresh token so that
+// operators can list and end it.
+type Session struct {
+	ID         string    `gorm:"primaryKey;size:36" json:"id"`
+	UserID     uint      `gorm:"index;not null" json:"user_id"`
+	TokenHash  string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	IP         string    `gorm:"size:64" json:"ip,omitempty"`
+	UserAgent  string    `gorm:"size:512" json:"user_agent,omitempty"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+	LastUsedAt time.Time `gorm:"not null" json:"last_used_at"`
+	ExpiresAt  time.Time `gorm:"index;not null" json:"expires_at"`
+}
+
+// SessionRepository hashes refresh tokens itself; callers pass them as issued.
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session, refreshToken string) error
+	// Rotate moves the session of oldToken to newToken. It returns
+	// ErrSessionRevoked if oldToken has no live session.
+	Rotate(ctx context.Context, oldToken, newToken string, expiresAt time.Time) error
+	ListByUser(ctx context.Context, userID uint) ([]Session, error)
+	// IsActive reports whether the session exists and has not expired.
+	IsActive(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByToken(ctx context.Context, refreshToken string) error
+	DeleteByUser(ctx context.Context, userID uint) (int64, error)
+	CountActive(ctx context.Context) (int64, error)
+}
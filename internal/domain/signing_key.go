@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	KeyPurposeAccess  = "access"
+	KeyPurposeRefresh = "refresh"
+)
+
+// SigningKey is a JWT signing secret identified by the token's "kid" header.
+// The newest key of each purpose signs new tokens; older ones keep verifying
+// tokens they signed until they are retired.
+type SigningKey struct {
+	ID        string    `gorm:"primaryKey;size:64" json:"kid"`
+	Purpose   string    `gorm:"size:16;index;not null" json:"purpose"`
+	Secret    []byte    `gorm:"not null" json:"-"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *SigningKey) error
+	List(ctx context.Context) ([]SigningKey, error)
+	Delete(ctx context.Context, id string) error
+}
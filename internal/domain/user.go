@@ -2,41 +2,97 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var (
+	ErrAccountDisabled    = errors.New("account is disabled")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailTaken         = errors.New("email already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrPasswordChangeRequired refuses requests of accounts flagged with
+	// MustChangePassword, other than those needed to change the password.
+	ErrPasswordChangeRequired = errors.New("password must be changed first")
+
+	// ErrInvalidToken covers every token that fails validation: malformed,
+	// badly signed or expired. The cause is wrapped for logs only.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrTokenRevoked = errors.New("token is blacklisted")
+)
+
+// ValidRole reports whether role is one the service knows.
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// User is an account. Emails are unique among live accounts only, so a
+// soft-deleted account does not block signing up again with its address.
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Name      string         `json:"name"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Email              string         `gorm:"not null;index:idx_users_email_active,unique,where:deleted_at IS NULL" json:"email"`
+	Password           string         `gorm:"not null" json:"-"`
+	Name               string         `json:"name"`
+	Role               string         `gorm:"size:32;not null;default:user" json:"role"`
+	MustChangePassword bool           `gorm:"not null;default:false" json:"must_change_password"`
+	DisabledAt         *time.Time     `json:"disabled_at,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// UserRepository returns ErrUserNotFound from every lookup and change of a
+// missing or deleted user.
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id uint) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id uint) error
+	UpdatePassword(ctx context.Context, id uint, hash string) error
+	// UpdatePasswordHash replaces the stored hash of an unchanged password,
+	// e.g. after upgrading its algorithm, without emitting an event.
+	UpdatePasswordHash(ctx context.Context, id uint, hash string) error
+	SetMustChangePassword(ctx context.Context, id uint, mustChange bool) error
+	UpdateRole(ctx context.Context, id uint, role string) error
+	// SetDisabled disables the account at the given time, or enables it
+	// again when disabledAt is nil.
+	SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error
 }
 
 type TokenClaims struct {
 	UserID uint
-	Expiry time.Time
+	Role   string
+	// SessionID is empty for tokens issued outside a tracked session.
+	SessionID string
+	// MustChangePassword is set on access tokens of accounts that had to
+	// change their password when the token was issued.
+	MustChangePassword bool
+	Expiry             time.Time
 }
 
 type TokenManager interface {
-	GenerateAccessToken(user *User) (string, error)
-	GenerateRefreshToken(user *User) (string, error)
+	// GenerateAccessToken and GenerateRefreshToken bind the token to the
+	// session sessionID, or to none when it is empty.
+	GenerateAccessToken(user *User, sessionID string) (string, error)
+	GenerateRefreshToken(user *User, sessionID string) (string, error)
+	// ValidateToken returns an error wrapping ErrInvalidToken for any token
+	// it rejects.
 	ValidateToken(token string, isRefresh bool) (*TokenClaims, error)
 }
 
 type PasswordHasher interface {
 	HashPassword(password string) (string, error)
 	CheckPassword(hash, password string) error
+	NeedsRehash(hash string) bool
 }
 
 type AuthUsecase interface {
@@ -45,4 +101,24 @@ type AuthUsecase interface {
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
 	Logout(ctx context.Context, accessToken string, refreshToken string) error
 	GetMe(ctx context.Context, userID uint) (*User, error)
+	UpdateProfile(ctx context.Context, userID uint, name string) (*User, error)
+	DeleteAccount(ctx context.Context, userID uint, password string) error
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error
+}
+
+// UserAdminUsecase holds the operator actions of the authctl CLI.
+type UserAdminUsecase interface {
+	CreateUser(ctx context.Context, user *User, password string) error
+	// SetPassword replaces the password and ends all of the user's sessions.
+	// With mustChange the user has to pick a new password after logging in.
+	SetPassword(ctx context.Context, userID uint, password string, mustChange bool) error
+	// SetDisabled disables or re-enables an account. Disabling also ends all
+	// of the user's sessions.
+	SetDisabled(ctx context.Context, userID uint, disabled bool) error
+	// SetRole changes the role and ends all of the user's sessions, whose
+	// tokens carry the old one.
+	SetRole(ctx context.Context, userID uint, role string) error
+	ListSessions(ctx context.Context, userID uint) ([]Session, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeSessions(ctx context.Context, userID uint) (int64, error)
 }
@@ -0,0 +1,29 @@
+package domain
+
+import "strings"
+
+// FieldError is one rejected request field. Rule is a stable identifier
+// (e.g. "required", "email"), Message is safe to show the client.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError rejects input the client can correct.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError rejects a single field.
+func NewValidationError(field, rule, message string) *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: field, Rule: rule, Message: message}}}
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = f.Field + ": " + f.Message
+	}
+	return "invalid input: " + strings.Join(fields, "; ")
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+var (
+	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrDeliveryNotRetryable = errors.New("only dead-lettered deliveries can be retried")
+	ErrDeliveryLeaseLost    = errors.New("webhook delivery lease expired and was claimed again")
+)
+
+type WebhookSubscription struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	URL        string    `gorm:"size:2048;not null" json:"url"`
+	Secret     string    `gorm:"type:text;not null" json:"-"`
+	EventTypes []string  `gorm:"type:text;serializer:json;not null" json:"event_types"`
+	Active     bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. The pair
+// (SubscriptionID, EventID) is unique so re-publishing an event is a no-op.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;uniqueIndex:idx_webhook_deliveries_sub_event" json:"subscription_id"`
+	EventID        string     `gorm:"size:64;not null;uniqueIndex:idx_webhook_deliveries_sub_event" json:"event_id"`
+	EventType      string     `gorm:"size:64;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:16;not null;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `gorm:"size:1024" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// LeasedUntil is the NextAttemptAt a claim set; it is zero for
+	// deliveries that were not claimed.
+	LeasedUntil time.Time `gorm:"-" json:"-"`
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	GetSubscription(ctx context.Context, id uint) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+
+	EnqueueDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	// ClaimDueDeliveries leases up to limit due pending deliveries by pushing
+	// their NextAttemptAt forward by lease, so other workers skip them.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	// UpdateDelivery stores the outcome of an attempt. For a claimed delivery
+	// it fails with ErrDeliveryLeaseLost once another worker has claimed it.
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uint) (*WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, status string, page, pageSize int) ([]WebhookDelivery, int64, error)
+}
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (*WebhookSubscription, string, error)
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, status string, page, pageSize int) ([]WebhookDelivery, int64, error)
+	RetryDelivery(ctx context.Context, id uint) (*WebhookDelivery, error)
+}
@@ -2,43 +2,81 @@ package infrastructure
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"go-auth-service/config"
 	"go-auth-service/internal/domain"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-func NewDatabase(cfg config.Config) *gorm.DB {
-	dsn := fmt.Sprintf("host=%s user=%s password='%s' dbname=%s port=%s sslmode=disable TimeZone=UTC",
-		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
+// NewDatabase opens the database selected by DB_DRIVER. Postgres schemas are
+// managed by the SQL migrations; SQLite, meant for local development and
+// tests, is created from the models with AutoMigrate as those migrations are
+// Postgres-specific.
+func NewDatabase(cfg config.Config) (*gorm.DB, error) {
+	// TranslateError turns driver-specific constraint errors into
+	// gorm.ErrDuplicatedKey so repositories can map them to domain errors.
+	gormConfig := &gorm.Config{Logger: newGormLogger(), TranslateError: true}
 
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		logger.Config{
-			LogLevel: logger.Error,
-			Colorful: false,
-		},
-	)
+	switch cfg.DBDriver {
+	case "postgres":
+		dsn := fmt.Sprintf("host=%s user=%s password='%s' dbname=%s port=%d sslmode=disable TimeZone=UTC",
+			cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: newLogger,
-	})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return db, nil
+	case "sqlite":
+		return openSQLite(cfg.DBPath, gormConfig)
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q", cfg.DBDriver)
 	}
+}
 
-	db.Debug()
+func openSQLite(path string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if path != ":memory:" {
+		dsn += "&_pragma=journal_mode(WAL)"
+	}
 
-	// Auto migrate
-	err = db.AutoMigrate(&domain.User{})
+	db, err := gorm.Open(sqlite.Open(dsn), gormConfig)
 	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
 	}
 
-	return db
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to ":memory:" is a separate database, and SQLite only
+	// has a single writer anyway.
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.AutoMigrate(
+		&domain.User{},
+		&domain.AuditEvent{},
+		&domain.AuditCheckpoint{},
+		&domain.WebhookSubscription{},
+		&domain.WebhookDelivery{},
+		&domain.OutboxEvent{},
+		&domain.PasswordHistoryEntry{},
+		&domain.RevokedToken{},
+		&domain.Session{},
+		&domain.SigningKey{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SQLite schema: %w", err)
+	}
+	// Older databases enforced unique emails across deleted accounts too;
+	// idx_users_email_active replaces that index.
+	if db.Migrator().HasIndex(&domain.User{}, "idx_users_email") {
+		if err := db.Migrator().DropIndex(&domain.User{}, "idx_users_email"); err != nil {
+			return nil, fmt.Errorf("failed to update SQLite schema: %w", err)
+		}
+	}
+	return db, nil
 }
@@ -0,0 +1,225 @@
+package infrastructure_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("Embedded", func(t *testing.T) {
+		loaded, err := infrastructure.LoadMigrations(migrations.FS)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, loaded)
+		for i, m := range loaded {
+			assert.Equal(t, uint(i+1), m.Version, "migration versions must be contiguous")
+			assert.NotEmpty(t, m.Up, "%d_%s up", m.Version, m.Name)
+			assert.NotEmpty(t, m.Down, "%d_%s down", m.Version, m.Name)
+		}
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000010_b.up.sql":   {Data: []byte("SELECT 10")},
+			"000002_a.up.sql":   {Data: []byte("SELECT 2")},
+			"000002_a.down.sql": {Data: []byte("SELECT -2")},
+		}
+
+		loaded, err := infrastructure.LoadMigrations(fsys)
+
+		assert.NoError(t, err)
+		assert.Len(t, loaded, 2)
+		assert.Equal(t, infrastructure.Migration{Version: 2, Name: "a", Up: "SELECT 2", Down: "SELECT -2"}, loaded[0])
+		assert.Equal(t, uint(10), loaded[1].Version)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, fsys := range map[string]fstest.MapFS{
+			"MissingUp":        {"000001_a.down.sql": {Data: []byte("SELECT 1")}},
+			"BadVersion":       {"x_a.up.sql": {Data: []byte("SELECT 1")}},
+			"BadDirection":     {"000001_a.sql": {Data: []byte("SELECT 1")}},
+			"DuplicateVersion": {"000001_a.up.sql": {Data: []byte("SELECT 1")}, "000001_b.up.sql": {Data: []byte("SELECT 1")}},
+		} {
+			_, err := infrastructure.LoadMigrations(fsys)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"000001_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY)")},
+		"000001_widgets.down.sql": {Data: []byte("DROP TABLE widgets")},
+		"000002_gadgets.up.sql":   {Data: []byte("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)")},
+		"000002_gadgets.down.sql": {Data: []byte("DROP TABLE gadgets")},
+		"000003_gizmos.up.sql":    {Data: []byte("CREATE TABLE gizmos (id INTEGER PRIMARY KEY)")},
+	}
+	setup := func(t *testing.T) (*gorm.DB, *infrastructure.Migrator) {
+		db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+		require.NoError(t, err)
+		m, err := infrastructure.NewMigrator(db, fsys)
+		require.NoError(t, err)
+		return db, m
+	}
+	tables := func(db *gorm.DB) []bool {
+		return []bool{db.Migrator().HasTable("widgets"), db.Migrator().HasTable("gadgets"), db.Migrator().HasTable("gizmos")}
+	}
+	pending := func(t *testing.T, m *infrastructure.Migrator) int {
+		n, err := m.Pending(ctx)
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("StatusIsReadOnly", func(t *testing.T) {
+		db, m := setup(t)
+
+		statuses, err := m.Status(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+		assert.Nil(t, statuses[0].AppliedAt)
+		assert.False(t, db.Migrator().HasTable("schema_migrations"))
+	})
+
+	t.Run("Up", func(t *testing.T) {
+		db, m := setup(t)
+
+		require.NoError(t, m.Up(ctx))
+
+		assert.Equal(t, []bool{true, true, true}, tables(db))
+		assert.Zero(t, pending(t, m))
+		assert.NoError(t, m.Up(ctx), "nothing left to apply")
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		db, m := setup(t)
+		require.NoError(t, m.To(ctx, 2))
+
+		require.NoError(t, m.Down(ctx, 1))
+
+		assert.Equal(t, []bool{true, false, false}, tables(db))
+		assert.Equal(t, 2, pending(t, m))
+
+		require.NoError(t, m.Up(ctx))
+		assert.ErrorIs(t, m.Down(ctx, 1), infrastructure.ErrNoDownMigration)
+		assert.Equal(t, []bool{true, true, true}, tables(db), "a failed rollback changes nothing")
+	})
+
+	t.Run("To", func(t *testing.T) {
+		db, m := setup(t)
+
+		require.NoError(t, m.To(ctx, 2))
+		assert.Equal(t, []bool{true, true, false}, tables(db))
+
+		require.NoError(t, m.To(ctx, 0))
+		assert.Equal(t, []bool{false, false, false}, tables(db))
+		assert.Equal(t, 3, pending(t, m))
+
+		assert.EqualError(t, m.To(ctx, 9), "unknown migration version 9")
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		db, m := setup(t)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 5)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = m.Up(ctx)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			assert.NoError(t, err, "every migration is applied once")
+		}
+		var applied int64
+		require.NoError(t, db.Table("schema_migrations").Count(&applied).Error)
+		assert.Equal(t, int64(3), applied)
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		db, m := setup(t)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		assert.Error(t, m.Up(cancelled))
+
+		require.NoError(t, m.Up(ctx), "the lock was released")
+		assert.Equal(t, []bool{true, true, true}, tables(db))
+	})
+}
+
+// TestMigrationsOnAutoMigratedSchema brings a database created by the GORM
+// AutoMigrate of earlier releases under the migration runner, as the README
+// describes. The scripts are Postgres SQL, so it runs only with
+// TEST_POSTGRES_DSN set, in a schema of its own.
+func TestMigrationsOnAutoMigratedSchema(t *testing.T) {
+	ctx := context.Background()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	schema := fmt.Sprintf("migrator_test_%d", time.Now().UnixNano())
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	// Unknown DSN settings are sent to the server as run-time parameters.
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	// The users model as AutoMigrate created it, with idx_users_email.
+	type user struct {
+		ID        uint   `gorm:"primaryKey"`
+		Email     string `gorm:"uniqueIndex;not null"`
+		Password  string `gorm:"not null"`
+		Name      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	require.NoError(t, db.AutoMigrate(&user{}))
+	require.True(t, db.Migrator().HasIndex(&user{}, "idx_users_email"))
+
+	m, err := infrastructure.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+
+	assert.False(t, db.Migrator().HasIndex(&user{}, "idx_users_email"))
+	insert := func() error {
+		return db.Exec("INSERT INTO users (email, password) VALUES ('reused@example.com', 'hash')").Error
+	}
+	require.NoError(t, insert())
+	assert.Error(t, insert(), "live accounts keep unique emails")
+	require.NoError(t, db.Exec("UPDATE users SET deleted_at = NOW()").Error)
+	assert.NoError(t, insert(), "a deleted account frees its email")
+}
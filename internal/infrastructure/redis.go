@@ -2,27 +2,127 @@ package infrastructure
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 
 	"go-auth-service/config"
 
 	"github.com/redis/go-redis/v9"
 )
 
-func NewRedisClient(cfg config.Config) *redis.Client {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
-		Password: cfg.RedisPassword,
-		DB:       0,
-	})
+// NewRedisClient connects to a cluster if REDIS_CLUSTER_ADDRS is set, else to
+// the Sentinel-managed master REDIS_SENTINEL_MASTER if set, else to the single
+// node at REDIS_HOST:REDIS_PORT.
+func NewRedisClient(cfg config.Config) (redis.UniversalClient, error) {
+	redis.SetLogger(redisLogger{})
 
-	_, err := rdb.Ping(context.Background()).Result()
+	tlsConfig, err := redisTLSConfig(cfg)
 	if err != nil {
-		log.Printf("Warning: Failed to connect to Redis: %v. Continuing without Redis.", err)
-		// We don't fatal here because Redis is optional/bonus, but better to have it working.
-		// If strict requirement, we would fatal.
+		return nil, fmt.Errorf("invalid Redis TLS configuration: %w", err)
 	}
 
-	return rdb
+	var rdb redis.UniversalClient
+	switch {
+	case cfg.RedisClusterAddrs != "":
+		if cfg.RedisDB != 0 {
+			return nil, fmt.Errorf("REDIS_DB must be 0 in cluster mode")
+		}
+		rdb = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        splitAddrs(cfg.RedisClusterAddrs),
+			Username:     cfg.RedisUsername,
+			Password:     cfg.RedisPassword,
+			TLSConfig:    tlsConfig,
+			PoolSize:     cfg.RedisPoolSize,
+			MinIdleConns: cfg.RedisMinIdleConns,
+			DialTimeout:  cfg.RedisDialTimeout,
+			ReadTimeout:  cfg.RedisReadTimeout,
+			WriteTimeout: cfg.RedisWriteTimeout,
+		})
+	case cfg.RedisSentinelMaster != "":
+		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.RedisSentinelMaster,
+			SentinelAddrs:    splitAddrs(cfg.RedisSentinelAddrs),
+			SentinelPassword: cfg.RedisSentinelPassword,
+			Username:         cfg.RedisUsername,
+			Password:         cfg.RedisPassword,
+			DB:               cfg.RedisDB,
+			TLSConfig:        tlsConfig,
+			PoolSize:         cfg.RedisPoolSize,
+			MinIdleConns:     cfg.RedisMinIdleConns,
+			DialTimeout:      cfg.RedisDialTimeout,
+			ReadTimeout:      cfg.RedisReadTimeout,
+			WriteTimeout:     cfg.RedisWriteTimeout,
+		})
+	default:
+		rdb = redis.NewClient(&redis.Options{
+			Addr:         net.JoinHostPort(cfg.RedisHost, strconv.Itoa(cfg.RedisPort)),
+			Username:     cfg.RedisUsername,
+			Password:     cfg.RedisPassword,
+			DB:           cfg.RedisDB,
+			TLSConfig:    tlsConfig,
+			PoolSize:     cfg.RedisPoolSize,
+			MinIdleConns: cfg.RedisMinIdleConns,
+			DialTimeout:  cfg.RedisDialTimeout,
+			ReadTimeout:  cfg.RedisReadTimeout,
+			WriteTimeout: cfg.RedisWriteTimeout,
+		})
+	}
+
+	_, err = rdb.Ping(context.Background()).Result()
+	if err != nil {
+		// Not fatal: Redis may come up later, and until then token revocation
+		// follows REVOCATION_FAILURE_MODE.
+		slog.Warn("failed to connect to Redis", "error", err)
+	}
+
+	return rdb, nil
+}
+
+func redisTLSConfig(cfg config.Config) (*tls.Config, error) {
+	if !cfg.RedisTLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.RedisTLSServerName,
+	}
+
+	if cfg.RedisTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.RedisTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read REDIS_TLS_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("REDIS_TLS_CA_FILE contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+func splitAddrs(addrs string) []string {
+	var out []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+// redisLogger sends go-redis's internal messages, such as pool dial failures,
+// to slog instead of its own stderr logger.
+type redisLogger struct{}
+
+func (redisLogger) Printf(ctx context.Context, format string, v ...interface{}) {
+	slog.WarnContext(ctx, "redis: "+fmt.Sprintf(format, v...))
 }
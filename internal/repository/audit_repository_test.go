@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+	signer := service.NewHMACSigner("audit-signing-key")
+	repo := repository.NewAuditRepository(db, signer, 3)
+	appendEvents := func(t *testing.T, n int) {
+		for i := 0; i < n; i++ {
+			require.NoError(t, repo.Append(ctx, &domain.AuditEvent{
+				CreatedAt: time.Now(),
+				Action:    domain.AuditActionLogin,
+				Outcome:   domain.AuditOutcomeSuccess,
+				Email:     "user@example.com",
+			}))
+		}
+	}
+
+	t.Run("SignHeadOfEmptyChain", func(t *testing.T) {
+		checkpoint, err := repo.SignHead(ctx)
+
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("IntervalCheckpoint", func(t *testing.T) {
+		appendEvents(t, 3)
+
+		checkpoints, err := repo.ListCheckpoints(ctx)
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 1)
+		assert.Equal(t, uint64(3), checkpoints[0].EventSeq)
+
+		checkpoint, err := repo.SignHead(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint, "the head already has a checkpoint")
+	})
+
+	t.Run("SignHead", func(t *testing.T) {
+		appendEvents(t, 2)
+
+		checkpoint, err := repo.SignHead(ctx)
+
+		require.NoError(t, err)
+		require.NotNil(t, checkpoint)
+		assert.Equal(t, uint64(5), checkpoint.EventSeq)
+		assert.NoError(t, signer.Verify(checkpoint.SigningPayload(), checkpoint.Signature))
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, result.Break)
+		assert.Equal(t, uint64(5), result.SignedHead)
+		assert.Zero(t, result.UnsignedEvents)
+	})
+
+	t.Run("TruncationAfterSignedHead", func(t *testing.T) {
+		require.NoError(t, db.Where("seq > ?", 4).Delete(&domain.AuditEvent{}).Error)
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(ctx)
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Equal(t, uint64(5), result.Break.Seq)
+	})
+}
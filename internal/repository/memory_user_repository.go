@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type memoryUserRepository struct {
+	publisher domain.EventPublisher
+
+	mu     sync.RWMutex
+	nextID uint
+	users  map[uint]domain.User
+}
+
+// NewMemoryUserRepository keeps users in process memory, for tests and local
+// development without a database. It returns the same errors as the GORM
+// repository (domain.ErrUserNotFound, domain.ErrEmailTaken) and, like it,
+// soft-deletes: deleted users stay stored but are not found and free their
+// email. If publisher is not nil it receives the events the GORM repository
+// writes to the outbox, for users that exist.
+func NewMemoryUserRepository(publisher domain.EventPublisher) domain.UserRepository {
+	return &memoryUserRepository{publisher: publisher, users: make(map[uint]domain.User)}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	for _, u := range r.users {
+		if u.Email == user.Email && !u.DeletedAt.Valid {
+			r.mu.Unlock()
+			return domain.ErrEmailTaken
+		}
+	}
+
+	r.nextID++
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt, user.UpdatedAt = now, now
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	r.users[user.ID] = *user
+	r.mu.Unlock()
+
+	r.publish(ctx, domain.NewUserEvent(domain.EventUserRegistered, user))
+	return nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if u.Email == email && !u.DeletedAt.Valid {
+			return &u, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid {
+		return nil, domain.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	if !r.modify(user.ID, func(u *domain.User) {
+		u.Name = user.Name
+	}) {
+		return domain.ErrUserNotFound
+	}
+	r.publish(ctx, domain.NewUserEvent(domain.EventUserUpdated, user))
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id uint) error {
+	var email string
+	if !r.modify(id, func(u *domain.User) {
+		u.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		email = u.Email
+	}) {
+		return domain.ErrUserNotFound
+	}
+	r.publish(ctx, domain.NewDomainEvent(domain.EventUserDeleted, id, map[string]any{"email": email}))
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	if !r.modify(id, func(u *domain.User) {
+		u.Password = hash
+		u.MustChangePassword = false
+	}) {
+		return domain.ErrUserNotFound
+	}
+	r.publish(ctx, domain.NewDomainEvent(domain.EventUserPasswordChanged, id, nil))
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePasswordHash(ctx context.Context, id uint, hash string) error {
+	if !r.modify(id, func(u *domain.User) {
+		u.Password = hash
+	}) {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) SetMustChangePassword(ctx context.Context, id uint, mustChange bool) error {
+	if !r.modify(id, func(u *domain.User) {
+		u.MustChangePassword = mustChange
+	}) {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	if !r.modify(id, func(u *domain.User) {
+		u.Role = role
+	}) {
+		return domain.ErrUserNotFound
+	}
+	r.publish(ctx, domain.NewDomainEvent(domain.EventUserRoleChanged, id, map[string]any{"role": role}))
+	return nil
+}
+
+func (r *memoryUserRepository) SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	if !r.modify(id, func(u *domain.User) {
+		u.DisabledAt = disabledAt
+	}) {
+		return domain.ErrUserNotFound
+	}
+
+	eventType := domain.EventUserEnabled
+	if disabledAt != nil {
+		eventType = domain.EventUserDisabled
+	}
+	r.publish(ctx, domain.NewDomainEvent(eventType, id, nil))
+	return nil
+}
+
+// modify applies fn to the stored user and reports whether it exists and is
+// not deleted.
+func (r *memoryUserRepository) modify(id uint, fn func(u *domain.User)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt.Valid {
+		return false
+	}
+	fn(&u)
+	u.UpdatedAt = time.Now()
+	r.users[id] = u
+	return true
+}
+
+func (r *memoryUserRepository) publish(ctx context.Context, event domain.DomainEvent) {
+	if r.publisher == nil {
+		return
+	}
+	if err := r.publisher.Publish(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to publish event", "event_type", event.Type, "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// InstrumentDB counts failed statements of every repository using db.
+// Not-found results are expected and not counted.
+func InstrumentDB(db *gorm.DB, metrics domain.Metrics) error {
+	count := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				metrics.DependencyError(domain.DependencyDatabase, operation)
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("metrics:create", count("create")),
+		callbacks.Query().After("gorm:query").Register("metrics:query", count("query")),
+		callbacks.Update().After("gorm:update").Register("metrics:update", count("update")),
+		callbacks.Delete().After("gorm:delete").Register("metrics:delete", count("delete")),
+		callbacks.Row().After("gorm:row").Register("metrics:row", count("row")),
+		callbacks.Raw().After("gorm:raw").Register("metrics:raw", count("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewRedisMetricsHook counts failed Redis commands by command name, and
+// failed dials. redis.Nil is a miss, not an error, and the CLIENT commands
+// go-redis sends on connect are best-effort: servers without them still work.
+func NewRedisMetricsHook(metrics domain.Metrics) redis.Hook {
+	return redisMetricsHook{metrics: metrics}
+}
+
+type redisMetricsHook struct {
+	metrics domain.Metrics
+}
+
+func (h redisMetricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.metrics.DependencyError(domain.DependencyRedis, "dial")
+		}
+		return conn, err
+	}
+}
+
+func (h redisMetricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if countedRedisError(cmd.Name(), err) {
+			h.metrics.DependencyError(domain.DependencyRedis, cmd.Name())
+		}
+		return err
+	}
+}
+
+func (h redisMetricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		if err != nil && slices.ContainsFunc(cmds, func(cmd redis.Cmder) bool { return countedRedisError(cmd.Name(), cmd.Err()) }) {
+			h.metrics.DependencyError(domain.DependencyRedis, "pipeline")
+		}
+		return err
+	}
+}
+
+func countedRedisError(command string, err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil) && command != "client"
+}
@@ -0,0 +1,120 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics records the dependency errors it is told about.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (m *recordingMetrics) AuthOutcome(flow, outcome, reason string)                        {}
+func (m *recordingMetrics) ObservePasswordHash(operation string, d time.Duration)           {}
+func (m *recordingMetrics) ObserveTokenValidation(refresh bool, d time.Duration, err error) {}
+func (m *recordingMetrics) RevocationCheck(source string, revoked bool, err error)          {}
+
+func (m *recordingMetrics) DependencyError(dependency, operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, dependency+":"+operation)
+}
+
+func (m *recordingMetrics) recorded() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.errors...)
+}
+
+func TestInstrumentDB(t *testing.T) {
+	ctx := context.Background()
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+	metrics := &recordingMetrics{}
+	require.NoError(t, repository.InstrumentDB(db, metrics))
+	users := repository.NewUserRepository(db)
+
+	t.Run("NotFoundIsNotCounted", func(t *testing.T) {
+		_, err := users.GetByID(ctx, 999)
+
+		require.Error(t, err)
+		assert.Empty(t, metrics.recorded())
+	})
+
+	t.Run("FailedStatementsAreCounted", func(t *testing.T) {
+		var n int64
+		require.Error(t, db.Table("no_such_table").Count(&n).Error)
+		require.Error(t, db.Exec("INSERT INTO no_such_table VALUES (1)").Error)
+
+		assert.Equal(t, []string{"database:query", "database:raw"}, metrics.recorded())
+	})
+
+	t.Run("SuccessIsNotCounted", func(t *testing.T) {
+		before := len(metrics.recorded())
+		require.NoError(t, users.Create(ctx, &domain.User{Email: "metrics@example.com", Password: "hash"}))
+
+		assert.Len(t, metrics.recorded(), before)
+	})
+}
+
+func TestRedisMetricsHook(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	metrics := &recordingMetrics{}
+	// miniredis rejects the CLIENT commands go-redis sends on connect, which
+	// must not count either.
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	client.AddHook(repository.NewRedisMetricsHook(metrics))
+	defer client.Close()
+
+	t.Run("MissIsNotCounted", func(t *testing.T) {
+		err := client.Get(ctx, "missing").Err()
+
+		require.True(t, errors.Is(err, redis.Nil))
+		assert.Empty(t, metrics.recorded())
+	})
+
+	t.Run("FailedCommandIsCounted", func(t *testing.T) {
+		require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+		require.Error(t, client.Incr(ctx, "key").Err())
+
+		assert.Equal(t, []string{"redis:incr"}, metrics.recorded())
+	})
+
+	t.Run("FailedPipelineIsCounted", func(t *testing.T) {
+		_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Incr(ctx, "key")
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"redis:incr", "redis:pipeline"}, metrics.recorded())
+	})
+
+	t.Run("FailedDialIsCounted", func(t *testing.T) {
+		addr := mr.Addr()
+		mr.Close()
+		down := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+		down.AddHook(repository.NewRedisMetricsHook(metrics))
+		defer down.Close()
+
+		require.Error(t, down.Ping(ctx).Err())
+
+		assert.Contains(t, metrics.recorded(), "redis:dial")
+	})
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Publish writes an event that is not tied to any other state change.
+func (r *outboxRepository) Publish(ctx context.Context, event domain.DomainEvent) error {
+	return appendOutbox(r.db.WithContext(ctx), event)
+}
+
+func (r *outboxRepository) ClaimDueEvents(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		query := tx.Where("published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id ASC").
+			Limit(limit)
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+			events[i].NextAttemptAt = now.Add(lease)
+		}
+
+		return tx.Model(&domain.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	return r.db.WithContext(ctx).
+		Model(event).
+		Select("PublishedAt", "NextAttemptAt", "DeadAt", "Attempts", "LastError").
+		Updates(event).Error
+}
+
+// appendOutbox must be called with the transaction that performs the state
+// change the event describes.
+func appendOutbox(tx *gorm.DB, event domain.DomainEvent) error {
+	row, err := domain.NewOutboxEvent(event)
+	if err != nil {
+		return err
+	}
+	return tx.Create(row).Error
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,53 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+	repo := repository.NewOutboxRepository(db)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Publish(ctx, domain.NewDomainEvent(domain.EventUserUpdated, uint(i), nil)))
+	}
+
+	claimed, err := repo.ClaimDueEvents(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Less(t, claimed[0].ID, claimed[1].ID, "oldest first")
+
+	// Leased events are skipped by the next claim.
+	rest, err := repo.ClaimDueEvents(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Greater(t, rest[0].ID, claimed[1].ID)
+
+	now := time.Now().UTC()
+	claimed[0].PublishedAt = &now
+	claimed[0].Attempts = 1
+	require.NoError(t, repo.UpdateEvent(ctx, &claimed[0]))
+
+	var stored domain.OutboxEvent
+	require.NoError(t, db.First(&stored, claimed[0].ID).Error)
+	assert.NotNil(t, stored.PublishedAt)
+	assert.Equal(t, 1, stored.Attempts)
+
+	// A lapsed lease makes the other events claimable again.
+	require.NoError(t, db.Model(&domain.OutboxEvent{}).Where("1 = 1").Update("next_attempt_at", now.Add(-time.Second)).Error)
+	again, err := repo.ClaimDueEvents(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, again, 2)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type passwordHistoryRepository struct {
+	db        *gorm.DB
+	depth     int
+	retention time.Duration
+}
+
+// NewPasswordHistoryRepository keeps the last depth hashes per user. Entries
+// older than retention are ignored and pruned; zero retention keeps them
+// until they fall out of the last depth.
+func NewPasswordHistoryRepository(db *gorm.DB, depth int, retention time.Duration) domain.PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db, depth: depth, retention: retention}
+}
+
+// Append records hash and prunes the user's history in the same transaction.
+func (r *passwordHistoryRepository) Append(ctx context.Context, userID uint, hash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry := &domain.PasswordHistoryEntry{UserID: userID, Hash: hash}
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		keep := tx.Model(&domain.PasswordHistoryEntry{}).
+			Select("id").
+			Where("user_id = ?", userID).
+			Order("created_at DESC, id DESC").
+			Limit(r.depth)
+
+		if r.retention > 0 {
+			return tx.Where("user_id = ? AND (id NOT IN (?) OR created_at < ?)", userID, keep, r.cutoff()).
+				Delete(&domain.PasswordHistoryEntry{}).Error
+		}
+		return tx.Where("user_id = ? AND id NOT IN (?)", userID, keep).
+			Delete(&domain.PasswordHistoryEntry{}).Error
+	})
+}
+
+func (r *passwordHistoryRepository) Recent(ctx context.Context, userID uint) ([]string, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.PasswordHistoryEntry{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(r.depth)
+	if r.retention > 0 {
+		query = query.Where("created_at >= ?", r.cutoff())
+	}
+
+	var hashes []string
+	if err := query.Pluck("hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+func (r *passwordHistoryRepository) cutoff() time.Time {
+	return time.Now().UTC().Add(-r.retention)
+}
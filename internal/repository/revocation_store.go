@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	constant "go-auth-service/internal/constants"
+	"go-auth-service/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revocationSweepInterval bounds how often the memory and Postgres stores
+// delete expired entries; the sweep piggybacks on Revoke.
+const revocationSweepInterval = time.Minute
+
+type redisRevocationStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisRevocationStore keeps revocations under the "blacklist:<token>"
+// keys used since the first release, with the token's remaining lifetime as
+// TTL. keyPrefix namespaces the keys when Redis is shared.
+func NewRedisRevocationStore(client redis.UniversalClient, keyPrefix string) domain.RevocationStore {
+	return &redisRevocationStore{client: client, keyPrefix: keyPrefix + constant.STR_BLACKLIST}
+}
+
+func (s *redisRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.keyPrefix+token, "true", ttl).Err()
+}
+
+func (s *redisRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.keyPrefix+token).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+type memoryRevocationStore struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRevocationStore keeps revocations in process memory. Revocations
+// are lost on restart and not shared between instances, so it is only meant
+// for tests and single-instance development setups.
+func NewMemoryRevocationStore() domain.RevocationStore {
+	return &memoryRevocationStore{tokens: make(map[string]time.Time)}
+}
+
+func (s *memoryRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil
+	}
+	s.tokens[token] = expiresAt
+
+	if now.Sub(s.lastSweep) >= revocationSweepInterval {
+		for t, exp := range s.tokens {
+			if !exp.After(now) {
+				delete(s.tokens, t)
+			}
+		}
+		s.lastSweep = now
+	}
+	return nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.tokens[token]
+	return ok && exp.After(time.Now()), nil
+}
+
+type postgresRevocationStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresRevocationStore keeps revocations in the revoked_tokens table,
+// for deployments without Redis.
+func NewPostgresRevocationStore(db *gorm.DB) domain.RevocationStore {
+	return &postgresRevocationStore{db: db}
+}
+
+func (s *postgresRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	if !expiresAt.After(time.Now()) {
+		return nil
+	}
+
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.RevokedToken{TokenHash: hashToken(token), ExpiresAt: expiresAt.UTC()}).Error
+	if err != nil {
+		return err
+	}
+
+	if s.sweepDue() {
+		return s.db.WithContext(ctx).Where("expires_at <= ?", time.Now().UTC()).Delete(&domain.RevokedToken{}).Error
+	}
+	return nil
+}
+
+func (s *postgresRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&domain.RevokedToken{}).
+		Where("token_hash = ? AND expires_at > ?", hashToken(token), time.Now().UTC()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *postgresRevocationStore) sweepDue() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastSweep) < revocationSweepInterval {
+		return false
+	}
+	s.lastSweep = time.Now()
+	return true
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
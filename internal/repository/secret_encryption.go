@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ReencryptSecrets rewrites every encrypted column with cipher, in one
+// transaction: values stored in plaintext get encrypted, and encrypted ones
+// get a data key wrapped by the provider's current key. It returns the number
+// of values rewritten. Values sealed before they were bound to their row are
+// bound in the process.
+func ReencryptSecrets(ctx context.Context, db *gorm.DB, cipher domain.SecretCipher) (int, error) {
+	count := 0
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var keys []domain.SigningKey
+		if err := tx.Select("id", "secret").Find(&keys).Error; err != nil {
+			return err
+		}
+		for _, key := range keys {
+			sealed, err := reencrypt(ctx, cipher, key.Secret, signingKeySecretAAD(key.ID))
+			if err != nil {
+				return fmt.Errorf("signing key %s: %w", key.ID, err)
+			}
+			if err := tx.Model(&domain.SigningKey{}).Where("id = ?", key.ID).UpdateColumn("secret", sealed).Error; err != nil {
+				return err
+			}
+			count++
+		}
+
+		var subs []domain.WebhookSubscription
+		if err := tx.Select("id", "secret").Find(&subs).Error; err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			sealed, err := reencrypt(ctx, cipher, []byte(sub.Secret), webhookSecretAAD(sub.ID))
+			if err != nil {
+				return fmt.Errorf("webhook subscription %d: %w", sub.ID, err)
+			}
+			if err := tx.Model(&domain.WebhookSubscription{}).Where("id = ?", sub.ID).UpdateColumn("secret", string(sealed)).Error; err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func reencrypt(ctx context.Context, cipher domain.SecretCipher, value, aad []byte) ([]byte, error) {
+	plaintext, err := cipher.Decrypt(ctx, value, aad)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.Encrypt(ctx, plaintext, aad)
+}
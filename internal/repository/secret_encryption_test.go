@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretEncryption(t *testing.T) {
+	ctx := context.Background()
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+
+	keyring := filepath.Join(t.TempDir(), "keyring")
+	require.NoError(t, os.WriteFile(keyring, []byte("1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"), 0o600))
+	provider, err := service.NewLocalKeyProvider(keyring)
+	require.NoError(t, err)
+	plain := service.NewEnvelopeCipher(nil)
+	sealing := service.NewEnvelopeCipher(provider)
+
+	// stored reads a secret column as it is in the database.
+	stored := func(t *testing.T, table, id string) string {
+		var secret string
+		require.NoError(t, db.Table(table).Select("secret").Where("id = ?", id).Scan(&secret).Error)
+		return secret
+	}
+
+	// Written before encryption was enabled.
+	sub := &domain.WebhookSubscription{URL: "https://example.com/hook", Secret: "whsec_plain", EventTypes: []string{"*"}, Active: true}
+	require.NoError(t, repository.NewWebhookRepository(db, plain).CreateSubscription(ctx, sub))
+	key := &domain.SigningKey{ID: "key-1", Purpose: "access", Secret: []byte("signing-secret"), CreatedAt: time.Now()}
+	require.NoError(t, repository.NewSigningKeyRepository(db, plain).Create(ctx, key))
+	require.Equal(t, "whsec_plain", stored(t, "webhook_subscriptions", "1"))
+
+	webhooks := repository.NewWebhookRepository(db, sealing)
+	signingKeys := repository.NewSigningKeyRepository(db, sealing)
+
+	t.Run("Reencrypt", func(t *testing.T) {
+		count, err := repository.ReencryptSecrets(ctx, db, sealing)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.True(t, strings.HasPrefix(stored(t, "webhook_subscriptions", "1"), "enc:v2:"))
+		assert.True(t, strings.HasPrefix(stored(t, "signing_keys", "key-1"), "enc:v2:"))
+
+		found, err := webhooks.GetSubscription(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "whsec_plain", found.Secret)
+		keys, err := signingKeys.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, []byte("signing-secret"), keys[0].Secret)
+	})
+
+	t.Run("RepositoriesEncrypt", func(t *testing.T) {
+		other := &domain.WebhookSubscription{URL: "https://example.com/other", Secret: "whsec_other", EventTypes: []string{"*"}, Active: true}
+		require.NoError(t, webhooks.CreateSubscription(ctx, other))
+		assert.Equal(t, "whsec_other", other.Secret, "the caller keeps the plaintext")
+
+		raw := stored(t, "webhook_subscriptions", "2")
+		assert.True(t, strings.HasPrefix(raw, "enc:v2:"))
+		assert.NotContains(t, raw, "whsec_other")
+
+		found, err := webhooks.GetSubscription(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "whsec_other", found.Secret)
+	})
+
+	t.Run("CopiedValueDoesNotDecrypt", func(t *testing.T) {
+		require.NoError(t, db.Table("webhook_subscriptions").Where("id = ?", 1).Update("secret", stored(t, "webhook_subscriptions", "2")).Error)
+
+		_, err := webhooks.GetSubscription(ctx, sub.ID)
+		assert.ErrorContains(t, err, "failed to decrypt secret of webhook subscription 1")
+	})
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) domain.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create also deletes the user's expired sessions, which keeps the table
+// bounded without a separate cleanup job.
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session, refreshToken string) error {
+	now := time.Now().UTC()
+	if session.ID == "" {
+		session.ID = uuid.NewString()
+	}
+	session.TokenHash = hashToken(refreshToken)
+	session.UserAgent = truncate(session.UserAgent, 512)
+	session.CreatedAt = now
+	session.LastUsedAt = now
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND expires_at <= ?", session.UserID, now).Delete(&domain.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(session).Error
+	})
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, oldToken, newToken string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("token_hash = ? AND expires_at > ?", hashToken(oldToken), now).
+		Updates(map[string]any{
+			"token_hash":   hashToken(newToken),
+			"last_used_at": now,
+			"expires_at":   expiresAt.UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (r *sessionRepository) ListByUser(ctx context.Context, userID uint) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now().UTC()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Session{}).
+		Where("id = ? AND expires_at > ?", id, time.Now().UTC()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&domain.Session{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) DeleteByToken(ctx context.Context, refreshToken string) error {
+	return r.db.WithContext(ctx).Delete(&domain.Session{}, "token_hash = ?", hashToken(refreshToken)).Error
+}
+
+func (r *sessionRepository) DeleteByUser(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&domain.Session{}, "user_id = ?", userID)
+	return result.RowsAffected, result.Error
+}
+
+func (r *sessionRepository) CountActive(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Session{}).Where("expires_at > ?", time.Now()).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func(t *testing.T) domain.SessionRepository {
+		db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+		require.NoError(t, err)
+		return repository.NewSessionRepository(db)
+	}
+
+	t.Run("Rotate", func(t *testing.T) {
+		repo := newRepo(t)
+		session := &domain.Session{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, repo.Create(ctx, session, "first"))
+		assert.NotEmpty(t, session.ID)
+
+		require.NoError(t, repo.Rotate(ctx, "first", "second", time.Now().Add(2*time.Hour)))
+		assert.ErrorIs(t, repo.Rotate(ctx, "first", "third", time.Now().Add(2*time.Hour)), domain.ErrSessionRevoked, "rotated token")
+
+		active, err := repo.IsActive(ctx, session.ID)
+		require.NoError(t, err)
+		assert.True(t, active, "rotation keeps the session")
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repo := newRepo(t)
+		expired := &domain.Session{UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+		require.NoError(t, repo.Create(ctx, expired, "expired"))
+
+		assert.ErrorIs(t, repo.Rotate(ctx, "expired", "new", time.Now().Add(time.Hour)), domain.ErrSessionRevoked)
+		active, err := repo.IsActive(ctx, expired.ID)
+		require.NoError(t, err)
+		assert.False(t, active)
+
+		// The next login of the user cleans it up.
+		require.NoError(t, repo.Create(ctx, &domain.Session{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, "live"))
+		count, err := repo.CountActive(ctx)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+		assert.Error(t, repo.Delete(ctx, expired.ID), "already deleted")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		first := &domain.Session{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		second := &domain.Session{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		other := &domain.Session{UserID: 2, ExpiresAt: time.Now().Add(time.Hour)}
+		require.NoError(t, repo.Create(ctx, first, "first"))
+		require.NoError(t, repo.Create(ctx, second, "second"))
+		require.NoError(t, repo.Create(ctx, other, "other"))
+
+		sessions, err := repo.ListByUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 2)
+
+		require.NoError(t, repo.Delete(ctx, first.ID))
+		assert.Error(t, repo.Delete(ctx, first.ID))
+		require.NoError(t, repo.DeleteByToken(ctx, "second"))
+
+		revoked, err := repo.DeleteByUser(ctx, 2)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, revoked)
+
+		for _, s := range []*domain.Session{first, second, other} {
+			active, err := repo.IsActive(ctx, s.ID)
+			require.NoError(t, err)
+			assert.False(t, active)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type signingKeyRepository struct {
+	db     *gorm.DB
+	cipher domain.SecretCipher
+}
+
+// NewSigningKeyRepository stores key secrets encrypted with cipher.
+func NewSigningKeyRepository(db *gorm.DB, cipher domain.SecretCipher) domain.SigningKeyRepository {
+	return &signingKeyRepository{db: db, cipher: cipher}
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	secret := key.Secret
+	sealed, err := r.cipher.Encrypt(ctx, secret, signingKeySecretAAD(key.ID))
+	if err != nil {
+		return err
+	}
+
+	key.Secret = sealed
+	err = r.db.WithContext(ctx).Create(key).Error
+	key.Secret = secret
+	return err
+}
+
+func (r *signingKeyRepository) List(ctx context.Context) ([]domain.SigningKey, error) {
+	var keys []domain.SigningKey
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		secret, err := r.cipher.Decrypt(ctx, keys[i].Secret, signingKeySecretAAD(keys[i].ID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key %s: %w", keys[i].ID, err)
+		}
+		keys[i].Secret = secret
+	}
+	return keys, nil
+}
+
+func signingKeySecretAAD(id string) []byte {
+	return domain.ColumnAAD("signing_keys", "secret", id)
+}
+
+func (r *signingKeyRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SigningKey{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanInstanceKey = "tracing:span"
+
+// TraceDB adds a client span for every statement run through db, named after
+// the GORM operation and table. Spans carry the SQL with placeholders, never
+// the bound values.
+func TraceDB(db *gorm.DB) error {
+	tracer := otel.Tracer("go-auth-service/internal/repository")
+	system := db.Dialector.Name()
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Statement.Context == nil {
+				return
+			}
+			ctx, span := tracer.Start(tx.Statement.Context, "gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemKey.String(system)))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanInstanceKey, span)
+		}
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(spanInstanceKey)
+			if !ok {
+				return
+			}
+			span := v.(trace.Span)
+			defer span.End()
+
+			// The table is only resolved once the statement has been built.
+			if table := tx.Statement.Table; table != "" {
+				span.SetName("gorm." + operation + " " + table)
+				span.SetAttributes(attribute.String("db.collection.name", table))
+			}
+			span.SetAttributes(
+				attribute.String("db.query.text", tx.Statement.SQL.String()),
+				attribute.Int64("db.response.rows_affected", tx.RowsAffected),
+			)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", before("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", after("create")),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", before("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", after("query")),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", before("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", after("update")),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", after("delete")),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", before("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", after("row")),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", after("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
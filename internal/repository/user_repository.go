@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go-auth-service/internal/domain"
 
 	"gorm.io/gorm"
 )
 
+// userRepository writes a domain event to the outbox in the same transaction
+// as every user mutation, so the change and its event commit or fail together.
 type userRepository struct {
 	db *gorm.DB
 }
@@ -17,14 +21,19 @@ func NewUserRepository(db *gorm.DB) domain.UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return translateUserError(err)
+		}
+		return appendOutbox(tx, domain.NewUserEvent(domain.EventUserRegistered, user))
+	})
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateUserError(err)
 	}
 	return &user, nil
 }
@@ -33,7 +42,96 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, er
 	var user domain.User
 	err := r.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateUserError(err)
 	}
 	return &user, nil
 }
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatedUser(tx.Model(user).Select("Name").Updates(user)); err != nil {
+			return err
+		}
+		return appendOutbox(tx, domain.NewUserEvent(domain.EventUserUpdated, user))
+	})
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.First(&user, id).Error; err != nil {
+			return translateUserError(err)
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, domain.NewDomainEvent(domain.EventUserDeleted, user.ID, map[string]any{"email": user.Email}))
+	})
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := updatedUser(tx.Model(&domain.User{ID: id}).Updates(map[string]any{
+			"password":             hash,
+			"must_change_password": false,
+		}))
+		if err != nil {
+			return err
+		}
+		return appendOutbox(tx, domain.NewDomainEvent(domain.EventUserPasswordChanged, id, nil))
+	})
+}
+
+func (r *userRepository) UpdatePasswordHash(ctx context.Context, id uint, hash string) error {
+	return updatedUser(r.db.WithContext(ctx).Model(&domain.User{ID: id}).Update("password", hash))
+}
+
+func (r *userRepository) SetMustChangePassword(ctx context.Context, id uint, mustChange bool) error {
+	return updatedUser(r.db.WithContext(ctx).Model(&domain.User{ID: id}).Update("must_change_password", mustChange))
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatedUser(tx.Model(&domain.User{ID: id}).Update("role", role)); err != nil {
+			return err
+		}
+		return appendOutbox(tx, domain.NewDomainEvent(domain.EventUserRoleChanged, id, map[string]any{"role": role}))
+	})
+}
+
+func (r *userRepository) SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatedUser(tx.Model(&domain.User{ID: id}).Update("disabled_at", disabledAt)); err != nil {
+			return err
+		}
+
+		eventType := domain.EventUserEnabled
+		if disabledAt != nil {
+			eventType = domain.EventUserDisabled
+		}
+		return appendOutbox(tx, domain.NewDomainEvent(eventType, id, nil))
+	})
+}
+
+// updatedUser returns the error of an update, or ErrUserNotFound if it
+// matched no live user. The outbox event of such an update is never written.
+func updatedUser(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// translateUserError maps GORM errors to the domain errors callers check for.
+func translateUserError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.ErrUserNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrEmailTaken
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The in-memory and GORM repositories must be interchangeable.
+func TestUserRepository(t *testing.T) {
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+
+	repos := map[string]domain.UserRepository{
+		"Memory": repository.NewMemoryUserRepository(nil),
+		"SQLite": repository.NewUserRepository(db),
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			user := &domain.User{Email: "test@example.com", Password: "hash", Name: "Test", Role: domain.RoleUser}
+			require.NoError(t, repo.Create(ctx, user))
+			assert.NotZero(t, user.ID)
+
+			assert.ErrorIs(t, repo.Create(ctx, &domain.User{Email: "test@example.com", Password: "hash"}), domain.ErrEmailTaken)
+
+			found, err := repo.GetByEmail(ctx, "test@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, found.ID)
+
+			require.NoError(t, repo.SetMustChangePassword(ctx, user.ID, true))
+			require.NoError(t, repo.UpdatePassword(ctx, user.ID, "new-hash"))
+			found, err = repo.GetByID(ctx, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "new-hash", found.Password)
+			assert.False(t, found.MustChangePassword)
+
+			found.Name = "Renamed"
+			require.NoError(t, repo.Update(ctx, found))
+			found, err = repo.GetByID(ctx, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Renamed", found.Name)
+
+			require.NoError(t, repo.Delete(ctx, user.ID))
+			_, err = repo.GetByID(ctx, user.ID)
+			assert.ErrorIs(t, err, domain.ErrUserNotFound)
+			_, err = repo.GetByEmail(ctx, "test@example.com")
+			assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+			assert.ErrorIs(t, repo.Delete(ctx, user.ID), domain.ErrUserNotFound, "already deleted")
+			assert.ErrorIs(t, repo.UpdateRole(ctx, user.ID, domain.RoleAdmin), domain.ErrUserNotFound)
+			_, err = repo.GetByID(ctx, user.ID)
+			assert.ErrorIs(t, err, domain.ErrUserNotFound, "updates do not restore a deleted user")
+
+			again := &domain.User{Email: "test@example.com", Password: "hash", Role: domain.RoleUser}
+			require.NoError(t, repo.Create(ctx, again), "a deleted account frees its email")
+			assert.NotEqual(t, user.ID, again.ID)
+		})
+	}
+}
+
+// recordingPublisher records the types of the events it is given.
+type recordingPublisher struct {
+	types []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event domain.DomainEvent) error {
+	p.types = append(p.types, event.Type)
+	return nil
+}
+
+// Both repositories emit an event for every change, and none for changes to
+// deleted or unknown users.
+func TestUserRepositoryEvents(t *testing.T) {
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+	publisher := &recordingPublisher{}
+
+	repos := map[string]struct {
+		repo   domain.UserRepository
+		events func() []string
+	}{
+		"Memory": {
+			repo:   repository.NewMemoryUserRepository(publisher),
+			events: func() []string { return publisher.types },
+		},
+		"SQLite": {
+			repo: repository.NewUserRepository(db),
+			events: func() []string {
+				var types []string
+				require.NoError(t, db.Model(&domain.OutboxEvent{}).Order("id").Pluck("event_type", &types).Error)
+				return types
+			},
+		},
+	}
+
+	for name, tc := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := tc.repo
+
+			user := &domain.User{Email: "events@example.com", Password: "hash"}
+			require.NoError(t, repo.Create(ctx, user))
+			require.NoError(t, repo.UpdateRole(ctx, user.ID, domain.RoleAdmin))
+			require.NoError(t, repo.Delete(ctx, user.ID))
+
+			for _, id := range []uint{user.ID, 999} {
+				assert.ErrorIs(t, repo.Update(ctx, &domain.User{ID: id, Name: "Ghost"}), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.UpdatePassword(ctx, id, "hash"), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.UpdatePasswordHash(ctx, id, "hash"), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.SetMustChangePassword(ctx, id, true), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.UpdateRole(ctx, id, domain.RoleUser), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.SetDisabled(ctx, id, nil), domain.ErrUserNotFound)
+				assert.ErrorIs(t, repo.Delete(ctx, id), domain.ErrUserNotFound)
+			}
+
+			assert.Equal(t, []string{domain.EventUserRegistered, domain.EventUserRoleChanged, domain.EventUserDeleted}, tc.events(),
+				"no events for deleted or unknown users")
+		})
+	}
+}
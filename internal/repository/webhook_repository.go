@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-auth-service/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db     *gorm.DB
+	cipher domain.SecretCipher
+}
+
+// NewWebhookRepository stores subscription secrets encrypted with cipher.
+func NewWebhookRepository(db *gorm.DB, cipher domain.SecretCipher) domain.WebhookRepository {
+	return &webhookRepository{db: db, cipher: cipher}
+}
+
+// CreateSubscription inserts the row before sealing its secret, as the
+// ciphertext is bound to the row ID.
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	secret := sub.Secret
+	defer func() { sub.Secret = secret }()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sub.Secret = ""
+		if err := tx.Create(sub).Error; err != nil {
+			return err
+		}
+
+		sealed, err := r.cipher.Encrypt(ctx, []byte(secret), webhookSecretAAD(sub.ID))
+		if err != nil {
+			return err
+		}
+		return tx.Model(sub).UpdateColumn("secret", string(sealed)).Error
+	})
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	err := r.db.WithContext(ctx).First(&sub, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decryptSecret(ctx, &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range subs {
+		if err := r.decryptSecret(ctx, &subs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+func (r *webhookRepository) decryptSecret(ctx context.Context, sub *domain.WebhookSubscription) error {
+	secret, err := r.cipher.Decrypt(ctx, []byte(sub.Secret), webhookSecretAAD(sub.ID))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt secret of webhook subscription %d: %w", sub.ID, err)
+	}
+	sub.Secret = string(secret)
+	return nil
+}
+
+func webhookSecretAAD(id uint) []byte {
+	return domain.ColumnAAD("webhook_subscriptions", "secret", strconv.FormatUint(uint64(id), 10))
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&domain.WebhookSubscription{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		// Stored with microsecond precision; UpdateDelivery compares against it.
+		leasedUntil := now.Add(lease).Truncate(time.Microsecond)
+
+		query := tx.Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit)
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = leasedUntil
+			deliveries[i].LeasedUntil = leasedUntil
+		}
+
+		return tx.Model(&domain.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leasedUntil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := r.db.WithContext(ctx).Model(delivery)
+	if !delivery.LeasedUntil.IsZero() {
+		// A new claim moved next_attempt_at; the result belongs to its owner.
+		query = query.Where("next_attempt_at = ?", delivery.LeasedUntil)
+	}
+
+	result := query.
+		Select("Status", "NextAttemptAt", "Attempts", "LastStatusCode", "LastError", "DeliveredAt").
+		Updates(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if !delivery.LeasedUntil.IsZero() && result.RowsAffected == 0 {
+		return domain.ErrDeliveryLeaseLost
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.WithContext(ctx).First(&delivery, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, status string, page, pageSize int) ([]domain.WebhookDelivery, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.WebhookDelivery{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []domain.WebhookDelivery
+	err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
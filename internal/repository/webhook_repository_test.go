@@ -0,0 +1,60 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveryLease(t *testing.T) {
+	ctx := context.Background()
+	db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+	require.NoError(t, err)
+	repo := repository.NewWebhookRepository(db, service.NewEnvelopeCipher(nil))
+
+	sub := &domain.WebhookSubscription{URL: "https://example.com/hook", Secret: "whsec_test", EventTypes: []string{"*"}, Active: true}
+	require.NoError(t, repo.CreateSubscription(ctx, sub))
+	require.NoError(t, repo.EnqueueDeliveries(ctx, []domain.WebhookDelivery{{
+		SubscriptionID: sub.ID,
+		EventID:        "event-1",
+		EventType:      domain.EventUserRegistered,
+		Payload:        "{}",
+		Status:         domain.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now().UTC(),
+	}}))
+
+	stale, err := repo.ClaimDueDeliveries(ctx, 10, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	none, err := repo.ClaimDueDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, none, "leased")
+
+	time.Sleep(20 * time.Millisecond)
+	current, err := repo.ClaimDueDeliveries(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, current, 1, "the lease lapsed")
+
+	now := time.Now().UTC()
+	current[0].Attempts = 1
+	current[0].Status = domain.WebhookDeliveryDelivered
+	current[0].DeliveredAt = &now
+	require.NoError(t, repo.UpdateDelivery(ctx, &current[0]))
+
+	stale[0].Attempts = 1
+	stale[0].LastError = "timeout"
+	assert.ErrorIs(t, repo.UpdateDelivery(ctx, &stale[0]), domain.ErrDeliveryLeaseLost)
+
+	stored, err := repo.GetDelivery(ctx, current[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryDelivered, stored.Status, "the stale worker did not overwrite the result")
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ASNTable maps IP addresses to the autonomous system announcing them. It is
+// read from a range file in the format published by iptoasn.com: one
+// "<first IP> <last IP> <AS number> ..." line per range, separated by tabs or
+// spaces, with any further columns ignored. Ranges with AS number 0 are not
+// routed and are skipped.
+type ASNTable struct {
+	ranges []asnRange
+}
+
+type asnRange struct {
+	first, last netip.Addr
+	asn         uint32
+}
+
+// LoadASNTable reads the range file at path.
+func LoadASNTable(path string) (*ASNTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open LOGIN_THREAT_ASN_FILE: %w", err)
+	}
+	defer f.Close()
+
+	t := &ASNTable{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("LOGIN_THREAT_ASN_FILE line %d: want <first IP> <last IP> <AS number>", lineNo)
+		}
+		first, err1 := netip.ParseAddr(fields[0])
+		last, err2 := netip.ParseAddr(fields[1])
+		asn, err3 := strconv.ParseUint(fields[2], 10, 32)
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return nil, fmt.Errorf("LOGIN_THREAT_ASN_FILE line %d: %w", lineNo, err)
+		}
+		first, last = first.Unmap(), last.Unmap()
+		if first.BitLen() != last.BitLen() || last.Less(first) {
+			return nil, fmt.Errorf("LOGIN_THREAT_ASN_FILE line %d: invalid range %s-%s", lineNo, first, last)
+		}
+		if asn == 0 {
+			continue
+		}
+		t.ranges = append(t.ranges, asnRange{first: first, last: last, asn: uint32(asn)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.ranges) == 0 {
+		return nil, errors.New("LOGIN_THREAT_ASN_FILE contains no ranges")
+	}
+
+	sort.Slice(t.ranges, func(i, j int) bool { return t.ranges[i].first.Less(t.ranges[j].first) })
+	return t, nil
+}
+
+// Lookup returns the AS number announcing ip, if any.
+func (t *ASNTable) Lookup(ip netip.Addr) (uint32, bool) {
+	ip = ip.Unmap()
+	// The last range starting at or before ip is the only one that can
+	// contain it, as ranges do not overlap.
+	i := sort.Search(len(t.ranges), func(i int) bool { return ip.Less(t.ranges[i].first) }) - 1
+	if i < 0 || t.ranges[i].last.Less(ip) {
+		return 0, false
+	}
+	return t.ranges[i].asn, true
+}
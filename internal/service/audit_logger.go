@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+type AuditLogger struct {
+	repo domain.AuditRepository
+}
+
+func NewAuditLogger(repo domain.AuditRepository) *AuditLogger {
+	return &AuditLogger{repo: repo}
+}
+
+// Log stamps the event with the current time and, unless already set, the
+// caller's IP and user agent before appending it.
+func (a *AuditLogger) Log(ctx context.Context, event *domain.AuditEvent) error {
+	client := domain.ClientInfoFromContext(ctx)
+	if event.IP == "" {
+		event.IP = client.IP
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+	event.UserAgent = truncate(event.UserAgent, 512)
+	event.Reason = truncate(event.Reason, 255)
+	event.CreatedAt = time.Now().UTC()
+
+	return a.repo.Append(ctx, event)
+}
+
+// Run signs the chain head every interval until ctx is done. The owner signs
+// it once more after the last event of the process with SignHead.
+func (a *AuditLogger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.SignHead(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to sign audit chain head", "error", err)
+			}
+		}
+	}
+}
+
+// SignHead writes a signed checkpoint of the chain head, so truncating the
+// chain can only go unnoticed for events written after it.
+func (a *AuditLogger) SignHead(ctx context.Context) error {
+	checkpoint, err := a.repo.SignHead(ctx)
+	if err == nil && checkpoint != nil {
+		slog.DebugContext(ctx, "signed audit chain head", "seq", checkpoint.EventSeq)
+	}
+	return err
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
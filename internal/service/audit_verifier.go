@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go-auth-service/internal/domain"
+)
+
+const auditVerifyBatchSize = 1000
+
+// AuditChainBreak describes the first point where the audit chain no longer
+// matches what was originally written.
+type AuditChainBreak struct {
+	Seq     uint64 `json:"seq"`
+	EventID uint   `json:"event_id,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// AuditVerification is the result of a chain walk. Events after SignedHead,
+// the latest checkpoint, are linked but not covered by a signature: deleting
+// them together would not be noticed.
+type AuditVerification struct {
+	EventsVerified      uint64           `json:"events_verified"`
+	CheckpointsVerified int              `json:"checkpoints_verified"`
+	Head                uint64           `json:"head_seq"`
+	SignedHead          uint64           `json:"signed_head_seq"`
+	UnsignedEvents      uint64           `json:"unsigned_events"`
+	Break               *AuditChainBreak `json:"break,omitempty"`
+}
+
+type AuditVerifier struct {
+	repo   domain.AuditRepository
+	signer domain.CheckpointSigner
+}
+
+func NewAuditVerifier(repo domain.AuditRepository, signer domain.CheckpointSigner) *AuditVerifier {
+	return &AuditVerifier{repo: repo, signer: signer}
+}
+
+// Verify walks the whole chain in order and stops at the first broken link.
+// Checkpoints are checked for a valid signature and for still matching the
+// event they were taken at. A chain ending before the latest checkpoint, the
+// signed head, has been truncated.
+func (v *AuditVerifier) Verify(ctx context.Context) (*AuditVerification, error) {
+	checkpoints, err := v.repo.ListCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[uint64]domain.AuditCheckpoint, len(checkpoints))
+	for _, cp := range checkpoints {
+		if err := v.signer.Verify(cp.SigningPayload(), cp.Signature); err != nil {
+			return &AuditVerification{Break: &AuditChainBreak{
+				Seq:    cp.EventSeq,
+				Reason: fmt.Sprintf("checkpoint %d has an invalid signature", cp.ID),
+			}}, nil
+		}
+		pending[cp.EventSeq] = cp
+	}
+
+	result := &AuditVerification{}
+	for _, cp := range checkpoints {
+		result.SignedHead = max(result.SignedHead, cp.EventSeq)
+	}
+	prevHash := ""
+	for {
+		events, err := v.repo.ListChain(ctx, result.Head, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			event := &events[i]
+			if brk := checkLink(event, result.Head, prevHash); brk != nil {
+				result.Break = brk
+				return result, nil
+			}
+
+			if cp, ok := pending[event.Seq]; ok {
+				if cp.EventHash != event.Hash {
+					result.Break = &AuditChainBreak{Seq: event.Seq, EventID: event.ID, Reason: "event does not match signed checkpoint"}
+					return result, nil
+				}
+				delete(pending, event.Seq)
+				result.CheckpointsVerified++
+			}
+
+			result.Head = event.Seq
+			result.EventsVerified++
+			prevHash = event.Hash
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	for seq := range pending {
+		if result.Break == nil || seq < result.Break.Seq {
+			result.Break = &AuditChainBreak{Seq: seq, Reason: "signed checkpoint refers to a missing event; the chain was truncated"}
+		}
+	}
+	if result.Head > result.SignedHead {
+		result.UnsignedEvents = result.Head - result.SignedHead
+	}
+
+	return result, nil
+}
+
+func checkLink(event *domain.AuditEvent, prevSeq uint64, prevHash string) *AuditChainBreak {
+	switch {
+	case event.Seq != prevSeq+1:
+		return &AuditChainBreak{Seq: prevSeq + 1, EventID: event.ID, Reason: fmt.Sprintf("sequence gap: expected %d, found %d", prevSeq+1, event.Seq)}
+	case event.PrevHash != prevHash:
+		return &AuditChainBreak{Seq: event.Seq, EventID: event.ID, Reason: "previous hash does not match the preceding event"}
+	case event.ComputeHash() != event.Hash:
+		return &AuditChainBreak{Seq: event.Seq, EventID: event.ID, Reason: "event contents do not match its hash"}
+	}
+	return nil
+}
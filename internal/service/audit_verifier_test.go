@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditRepository chains events the same way the GORM repository does.
+type fakeAuditRepository struct {
+	events      []domain.AuditEvent
+	checkpoints []domain.AuditCheckpoint
+}
+
+func (r *fakeAuditRepository) Append(ctx context.Context, event *domain.AuditEvent) error {
+	event.ID = uint(len(r.events) + 1)
+	event.Seq = uint64(len(r.events) + 1)
+	if len(r.events) > 0 {
+		event.PrevHash = r.events[len(r.events)-1].Hash
+	}
+	event.Hash = event.ComputeHash()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeAuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	return r.events, int64(len(r.events)), nil
+}
+
+func (r *fakeAuditRepository) ListChain(ctx context.Context, afterSeq uint64, limit int) ([]domain.AuditEvent, error) {
+	var out []domain.AuditEvent
+	for _, e := range r.events {
+		if e.Seq > afterSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeAuditRepository) ListCheckpoints(ctx context.Context) ([]domain.AuditCheckpoint, error) {
+	return r.checkpoints, nil
+}
+
+func (r *fakeAuditRepository) SignHead(ctx context.Context) (*domain.AuditCheckpoint, error) {
+	return nil, nil
+}
+
+// appendEvents appends n login events to repo.
+func appendEvents(t *testing.T, repo *fakeAuditRepository, n int) {
+	for i := 0; i < n; i++ {
+		require.NoError(t, repo.Append(context.Background(), &domain.AuditEvent{
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, len(repo.events), 0, time.UTC),
+			Action:    domain.AuditActionLogin,
+			Outcome:   domain.AuditOutcomeSuccess,
+			Email:     "user@example.com",
+		}))
+	}
+}
+
+// signHead adds a checkpoint of the current head of repo.
+func signHead(t *testing.T, repo *fakeAuditRepository, signer *service.HMACSigner) {
+	last := repo.events[len(repo.events)-1]
+	cp := domain.AuditCheckpoint{ID: uint(len(repo.checkpoints) + 1), EventSeq: last.Seq, EventHash: last.Hash}
+	sig, err := signer.Sign(cp.SigningPayload())
+	require.NoError(t, err)
+	cp.Signature = sig
+	repo.checkpoints = append(repo.checkpoints, cp)
+}
+
+func newChain(t *testing.T, signer *service.HMACSigner, n int) *fakeAuditRepository {
+	repo := &fakeAuditRepository{}
+	appendEvents(t, repo, n)
+	signHead(t, repo, signer)
+	return repo
+}
+
+func TestAuditVerifier(t *testing.T) {
+	signer := service.NewHMACSigner("test-signing-key")
+
+	t.Run("IntactChain", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Break)
+		assert.Equal(t, uint64(5), result.EventsVerified)
+		assert.Equal(t, 1, result.CheckpointsVerified)
+		assert.Equal(t, uint64(5), result.SignedHead)
+		assert.Zero(t, result.UnsignedEvents)
+	})
+
+	t.Run("UnsignedTail", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+		appendEvents(t, repo, 2)
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Break)
+		assert.Equal(t, uint64(7), result.Head)
+		assert.Equal(t, uint64(5), result.SignedHead)
+		assert.Equal(t, uint64(2), result.UnsignedEvents)
+	})
+
+	t.Run("TruncatedAfterSignedHead", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+		appendEvents(t, repo, 2)
+		signHead(t, repo, signer)
+		repo.events = repo.events[:6]
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Equal(t, uint64(7), result.Break.Seq)
+		assert.Contains(t, result.Break.Reason, "truncated")
+	})
+
+	t.Run("ModifiedEvent", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+		repo.events[2].Outcome = domain.AuditOutcomeFailure
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Equal(t, uint64(3), result.Break.Seq)
+	})
+
+	t.Run("DeletedEvent", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+		repo.events = append(repo.events[:1], repo.events[2:]...)
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Equal(t, uint64(2), result.Break.Seq)
+	})
+
+	t.Run("TruncatedTail", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+		repo.events = repo.events[:3]
+
+		result, err := service.NewAuditVerifier(repo, signer).Verify(context.Background())
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Equal(t, uint64(5), result.Break.Seq)
+	})
+
+	t.Run("ForgedCheckpoint", func(t *testing.T) {
+		repo := newChain(t, signer, 5)
+
+		result, err := service.NewAuditVerifier(repo, service.NewHMACSigner("other-key")).Verify(context.Background())
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Break)
+		assert.Contains(t, result.Break.Reason, "invalid signature")
+	})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-auth-service/internal/domain"
+)
+
+const hibpPrefixLength = 5
+
+// BreachedPasswordChecker looks passwords up in an offline copy of the Have I
+// Been Pwned corpus, so no password or hash prefix ever leaves the host.
+// Two layouts are supported:
+//
+//   - a directory of range files as written by the PwnedPasswordsDownloader,
+//     one "<5 hex prefix>.txt" file per prefix holding "<35 hex suffix>:<count>"
+//     lines, the same data the k-anonymity range API serves;
+//   - a single "<40 hex SHA-1>:<count>" file sorted by hash, which is searched
+//     in place with a binary search instead of being loaded into memory.
+type BreachedPasswordChecker struct {
+	path     string
+	isDir    bool
+	minCount int
+}
+
+// NewBreachedPasswordChecker opens the corpus at path. Passwords seen fewer
+// than minCount times are not reported as breached.
+func NewBreachedPasswordChecker(path string, minCount int) (*BreachedPasswordChecker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PASSWORD_BREACH_CORPUS: %w", err)
+	}
+
+	if minCount < 1 {
+		minCount = 1
+	}
+
+	return &BreachedPasswordChecker{path: path, isDir: info.IsDir(), minCount: minCount}, nil
+}
+
+func (b *BreachedPasswordChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	count, err := b.Count(password)
+	if err != nil {
+		return false, err
+	}
+	return count >= b.minCount, nil
+}
+
+// Check makes the checker usable as a password policy rule.
+func (b *BreachedPasswordChecker) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	breached, err := b.IsBreached(ctx, c.Password)
+	if err != nil || !breached {
+		return nil, err
+	}
+
+	return []domain.PolicyViolation{{
+		Rule:    "breached",
+		Message: "Password has appeared in a data breach; choose a different one",
+	}}, nil
+}
+
+// Count returns how many times the password appears in the corpus.
+func (b *BreachedPasswordChecker) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if b.isDir {
+		return b.countInRangeFile(hash)
+	}
+	return b.countInSortedFile(hash)
+}
+
+func (b *BreachedPasswordChecker) countInRangeFile(hash string) (int, error) {
+	prefix, suffix := hash[:hibpPrefixLength], hash[hibpPrefixLength:]
+
+	f, err := os.Open(filepath.Join(b.path, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(filepath.Join(b.path, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineHash, count := parseHIBPLine(scanner.Text())
+		if strings.EqualFold(lineHash, suffix) {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// countInSortedFile binary searches over byte offsets; each probe reads the
+// first complete line at or after the offset.
+func (b *BreachedPasswordChecker) countInSortedFile(hash string) (int, error) {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := lineAtOrAfter(f, mid, info.Size())
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		lineHash, count := parseHIBPLine(line)
+		switch cmp := strings.Compare(strings.ToUpper(lineHash), hash); {
+		case cmp == 0:
+			return count, nil
+		case cmp < 0:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineAtOrAfter returns the offset and content (without the newline) of the
+// first line starting at or after offset. At end of file start equals size.
+func lineAtOrAfter(f *os.File, offset, size int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Start reading one byte early so a line beginning exactly at offset
+		// is not skipped.
+		r := bufio.NewReader(io.NewSectionReader(f, offset-1, size-offset+1))
+		skipped, err := r.ReadString('\n')
+		if err == io.EOF {
+			return size, "", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		start = offset - 1 + int64(len(skipped))
+	}
+	if start >= size {
+		return size, "", nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, start, size-start))
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", err
+	}
+	return start, strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseHIBPLine splits "<hash>:<count>"; a line without a count counts once.
+func parseHIBPLine(line string) (string, int) {
+	hash, countStr, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok {
+		return hash, 1
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		return hash, 1
+	}
+	return hash, count
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const passwordSHA1 = "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestBreachedPasswordChecker(t *testing.T) {
+	ctx := context.Background()
+
+	rangeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rangeDir, passwordSHA1[:5]+".txt"), []byte(
+		"1D2DA4053E34E76F6576ED1DA63134B5E2A:2\r\n"+passwordSHA1[5:]+":9545824\r\n"+"1E4C9B93F3F0682250B6CF8331B7EE68FD9:3\r\n",
+	), 0o644))
+
+	sortedFile := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, os.WriteFile(sortedFile, []byte(
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4:10\n"+
+			"00000000A8DAE4228F821FB418F59826079BF368:4\n"+
+			"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD7:1\n"+
+			passwordSHA1+":9545824\n"+
+			"7C4A8D09CA3762AF61E59520943DC26494F8941B:37359195\n"+
+			"FFFFFFFEE791CBAC0F6305CAF0CEE06BBE131160:2\n",
+	), 0o644))
+
+	for name, path := range map[string]string{"RangeDirectory": rangeDir, "SortedFile": sortedFile} {
+		t.Run(name, func(t *testing.T) {
+			checker, err := service.NewBreachedPasswordChecker(path, 1)
+			require.NoError(t, err)
+
+			count, err := checker.Count("password")
+			require.NoError(t, err)
+			assert.Equal(t, 9545824, count)
+
+			breached, err := checker.IsBreached(ctx, "violet-ferry-lantern-92")
+			require.NoError(t, err)
+			assert.False(t, breached)
+
+			violations, err := checker.Check(ctx, domain.PasswordCandidate{Password: "password"})
+			require.NoError(t, err)
+			require.Len(t, violations, 1)
+			assert.Equal(t, "breached", violations[0].Rule)
+		})
+	}
+
+	t.Run("SortedFileFindsEveryEntry", func(t *testing.T) {
+		checker, err := service.NewBreachedPasswordChecker(sortedFile, 1)
+		require.NoError(t, err)
+
+		// SHA-1("123456") is on the last-but-one line.
+		count, err := checker.Count("123456")
+		require.NoError(t, err)
+		assert.Equal(t, 37359195, count)
+	})
+
+	t.Run("MinCount", func(t *testing.T) {
+		checker, err := service.NewBreachedPasswordChecker(rangeDir, 10_000_000)
+		require.NoError(t, err)
+
+		breached, err := checker.IsBreached(ctx, "password")
+		require.NoError(t, err)
+		assert.False(t, breached)
+	})
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-auth-service/config"
+)
+
+const challengeVerifyTimeout = 5 * time.Second
+
+// SiteVerifier checks challenge responses against a siteverify endpoint.
+// Cloudflare Turnstile, hCaptcha and reCAPTCHA share the protocol: a form
+// POST of the secret, the client's response and its IP, answered with
+// {"success": true|false, ...}.
+type SiteVerifier struct {
+	client    *http.Client
+	verifyURL string
+	secret    string
+}
+
+func NewSiteVerifier(cfg config.Config) *SiteVerifier {
+	return &SiteVerifier{
+		client:    &http.Client{Timeout: challengeVerifyTimeout},
+		verifyURL: cfg.LoginThreatChallengeVerifyURL,
+		secret:    cfg.LoginThreatChallengeSecret,
+	}
+}
+
+func (v *SiteVerifier) Verify(ctx context.Context, response, ip string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {response}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("siteverify responded with status %d", resp.StatusCode)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return false, fmt.Errorf("invalid siteverify response: %w", err)
+	}
+	return result.Success, nil
+}
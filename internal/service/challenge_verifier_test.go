@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		switch {
+		case r.PostForm.Get("secret") != "site-secret":
+			w.WriteHeader(http.StatusForbidden)
+		case r.PostForm.Get("response") == "solved" && r.PostForm.Get("remoteip") == "203.0.113.7":
+			w.Write([]byte(`{"success": true}`))
+		default:
+			w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}
+	}))
+	defer server.Close()
+	newVerifier := func(secret string) *service.SiteVerifier {
+		return service.NewSiteVerifier(config.Config{LoginThreatChallengeVerifyURL: server.URL, LoginThreatChallengeSecret: secret})
+	}
+
+	t.Run("Solved", func(t *testing.T) {
+		solved, err := newVerifier("site-secret").Verify(ctx, "solved", "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.True(t, solved)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		solved, err := newVerifier("site-secret").Verify(ctx, "forged", "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.False(t, solved)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := newVerifier("wrong").Verify(ctx, "solved", "203.0.113.7")
+
+		assert.EqualError(t, err, "siteverify responded with status 403")
+	})
+}
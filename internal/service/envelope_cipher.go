@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+)
+
+// envelopePrefix marks encrypted values, so values stored before encryption
+// was enabled can still be read. v1 values were sealed without additional
+// data; they stay readable until authctl secrets reencrypt rewrites them.
+var (
+	envelopePrefix   = []byte("enc:v2:")
+	envelopePrefixV1 = []byte("enc:v1:")
+)
+
+// EnvelopeCipher encrypts every value under its own data key from a
+// KeyProvider and stores the wrapped data key with it, as
+// "enc:v2:" + base64(<wrapped key length><wrapped key><nonce><sealed value>),
+// authenticating the caller's additional data with the value.
+// Without a provider values are stored in plaintext.
+type EnvelopeCipher struct {
+	provider domain.KeyProvider
+}
+
+func NewEnvelopeCipher(provider domain.KeyProvider) *EnvelopeCipher {
+	return &EnvelopeCipher{provider: provider}
+}
+
+// NewSecretCipher returns the cipher for ENCRYPTION_KEY_PROVIDER.
+func NewSecretCipher(cfg config.Config) (*EnvelopeCipher, error) {
+	switch cfg.EncryptionKeyProvider {
+	case "none":
+		return NewEnvelopeCipher(nil), nil
+	case "local":
+		provider, err := NewLocalKeyProvider(cfg.EncryptionLocalKeyring)
+		if err != nil {
+			return nil, err
+		}
+		return NewEnvelopeCipher(provider), nil
+	default:
+		return nil, fmt.Errorf("unknown ENCRYPTION_KEY_PROVIDER %q", cfg.EncryptionKeyProvider)
+	}
+}
+
+func (c *EnvelopeCipher) Encrypt(ctx context.Context, plaintext, aad []byte) ([]byte, error) {
+	if c.provider == nil {
+		return bytes.Clone(plaintext), nil
+	}
+
+	key, wrapped, err := c.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if len(wrapped) > math.MaxUint16 {
+		return nil, errors.New("wrapped data key is too long")
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	blob := binary.BigEndian.AppendUint16(nil, uint16(len(wrapped)))
+	blob = append(blob, wrapped...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	blob = append(blob, nonce...)
+	blob = aead.Seal(blob, nonce, plaintext, aad)
+
+	out := append(bytes.Clone(envelopePrefix), make([]byte, base64.RawStdEncoding.EncodedLen(len(blob)))...)
+	base64.RawStdEncoding.Encode(out[len(envelopePrefix):], blob)
+	return out, nil
+}
+
+func (c *EnvelopeCipher) Decrypt(ctx context.Context, ciphertext, aad []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(ciphertext, envelopePrefix)
+	if !ok {
+		if encoded, ok = bytes.CutPrefix(ciphertext, envelopePrefixV1); !ok {
+			return bytes.Clone(ciphertext), nil
+		}
+		aad = nil
+	}
+	if c.provider == nil {
+		return nil, domain.ErrNoKeyProvider
+	}
+
+	blob := make([]byte, base64.RawStdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.RawStdEncoding.Decode(blob, encoded)
+	if err != nil || n < 2 {
+		return nil, errors.New("malformed encrypted value")
+	}
+	blob = blob[:n]
+
+	wrappedLen := int(binary.BigEndian.Uint16(blob))
+	if len(blob) < 2+wrappedLen {
+		return nil, errors.New("malformed encrypted value")
+	}
+	key, err := c.provider.UnwrapDataKey(ctx, blob[2:2+wrappedLen])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := blob[2+wrappedLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	keyringV1 = "1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"
+	keyringV2 = "2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=\n"
+)
+
+func newLocalKeyProvider(t *testing.T, keyring string) *service.LocalKeyProvider {
+	path := filepath.Join(t.TempDir(), "keyring")
+	require.NoError(t, os.WriteFile(path, []byte(keyring), 0o600))
+	provider, err := service.NewLocalKeyProvider(path)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestEnvelopeCipher(t *testing.T) {
+	ctx := context.Background()
+	newCipher := func(t *testing.T, keyring string) *service.EnvelopeCipher {
+		return service.NewEnvelopeCipher(newLocalKeyProvider(t, keyring))
+	}
+
+	v1 := newCipher(t, keyringV1)
+	secret := []byte("webhook-signing-secret")
+	aad := domain.ColumnAAD("webhook_subscriptions", "secret", "1")
+	sealed, err := v1.Encrypt(ctx, secret, aad)
+	require.NoError(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		assert.True(t, bytes.HasPrefix(sealed, []byte("enc:v2:")))
+		assert.NotContains(t, string(sealed), string(secret))
+
+		again, err := v1.Encrypt(ctx, secret, aad)
+		require.NoError(t, err)
+		assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+		opened, err := v1.Decrypt(ctx, sealed, aad)
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+	})
+
+	t.Run("BoundToColumn", func(t *testing.T) {
+		_, err := v1.Decrypt(ctx, sealed, domain.ColumnAAD("webhook_subscriptions", "secret", "2"))
+		assert.Error(t, err, "another row")
+
+		_, err = v1.Decrypt(ctx, sealed, domain.ColumnAAD("signing_keys", "secret", "1"))
+		assert.Error(t, err, "another table")
+	})
+
+	t.Run("UnboundValuesAreReadable", func(t *testing.T) {
+		// Values from before AAD binding: "enc:v1:" and sealed without
+		// additional data.
+		key, wrapped, err := newLocalKeyProvider(t, keyringV1).GenerateDataKey(ctx)
+		require.NoError(t, err)
+		block, err := aes.NewCipher(key)
+		require.NoError(t, err)
+		aead, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		nonce := make([]byte, aead.NonceSize())
+		_, err = rand.Read(nonce)
+		require.NoError(t, err)
+		blob := binary.BigEndian.AppendUint16(nil, uint16(len(wrapped)))
+		blob = append(append(blob, wrapped...), nonce...)
+		blob = aead.Seal(blob, nonce, secret, nil)
+		legacy := []byte("enc:v1:" + base64.RawStdEncoding.EncodeToString(blob))
+
+		opened, err := v1.Decrypt(ctx, legacy, aad)
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+	})
+
+	t.Run("RotationKeepsOldValuesReadable", func(t *testing.T) {
+		v2 := newCipher(t, keyringV1+keyringV2)
+
+		opened, err := v2.Decrypt(ctx, sealed, aad)
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+
+		resealed, err := v2.Encrypt(ctx, secret, aad)
+		require.NoError(t, err)
+		_, err = v1.Decrypt(ctx, resealed, aad)
+		assert.ErrorContains(t, err, "keyring version 2")
+	})
+
+	t.Run("PlaintextIsReadAsIs", func(t *testing.T) {
+		opened, err := v1.Decrypt(ctx, []byte("stored-before-encryption"), aad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("stored-before-encryption"), opened)
+	})
+
+	t.Run("WithoutProvider", func(t *testing.T) {
+		plain := service.NewEnvelopeCipher(nil)
+
+		stored, err := plain.Encrypt(ctx, secret, aad)
+		require.NoError(t, err)
+		assert.Equal(t, secret, stored)
+
+		_, err = plain.Decrypt(ctx, sealed, aad)
+		assert.ErrorIs(t, err, domain.ErrNoKeyProvider)
+	})
+
+	t.Run("TamperingIsDetected", func(t *testing.T) {
+		tampered := bytes.Clone(sealed)
+		tampered[len(tampered)-2] ^= 1
+
+		_, err := v1.Decrypt(ctx, tampered, aad)
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidKeyring", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring")
+		require.NoError(t, os.WriteFile(path, []byte("1:c2hvcnQ=\n"), 0o600))
+
+		_, err := service.NewLocalKeyProvider(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("MalformedKeyringLineIsNotEchoed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keyring")
+		require.NoError(t, os.WriteFile(path, []byte(keyringV1+"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"), 0o600))
+
+		_, err := service.NewLocalKeyProvider(path)
+
+		assert.ErrorContains(t, err, "line 2")
+		assert.NotContains(t, err.Error(), "MDEyMzQ1")
+	})
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"go-auth-service/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamSink appends events to a Redis Stream. The idempotency key is
+// written as a field so consumer groups can de-duplicate redeliveries.
+type RedisStreamSink struct {
+	redisClient redis.UniversalClient
+	stream      string
+	maxLen      int64
+}
+
+func NewRedisStreamSink(redisClient redis.UniversalClient, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		redisClient: redisClient,
+		stream:      stream,
+		maxLen:      maxLen,
+	}
+}
+
+func (s *RedisStreamSink) Name() string {
+	return "redis_stream"
+}
+
+func (s *RedisStreamSink) Send(ctx context.Context, event domain.DomainEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"idempotency_key": event.ID,
+			"type":            event.Type,
+			"payload":         payload,
+		},
+	}).Err()
+}
+
+// StdoutSink writes one JSON object per line; useful for local development
+// and for shipping events through a log pipeline.
+type StdoutSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{w: w}
+}
+
+func (s *StdoutSink) Name() string {
+	return "stdout"
+}
+
+func (s *StdoutSink) Send(ctx context.Context, event domain.DomainEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return json.NewEncoder(s.w).Encode(event)
+}
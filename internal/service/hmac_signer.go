@@ -0,0 +1,39 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// HMACSigner signs payloads with HMAC-SHA256 under the service's signing key.
+type HMACSigner struct {
+	key []byte
+}
+
+func NewHMACSigner(key string) *HMACSigner {
+	return &HMACSigner{key: []byte(key)}
+}
+
+func (s *HMACSigner) Sign(payload []byte) (string, error) {
+	if len(s.key) == 0 {
+		return "", errors.New("signing key is not configured")
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (s *HMACSigner) Verify(payload []byte, signature string) error {
+	expected, err := s.Sign(payload)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+)
+
+const (
+	signingKeySize = 32
+	// keyRingMissReload bounds how often a token with an unknown kid, e.g.
+	// one signed by an instance that saw a rotation first, forces a reload.
+	keyRingMissReload = 5 * time.Second
+)
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// KeyRing holds the JWT signing keys. Keys created with authctl live in the
+// database and reach every instance on its next reload; for a purpose without
+// any, JWT_SECRET or JWT_REFRESH_SECRET signs tokens without a kid. Tokens
+// without a kid are verified with those secrets for as long as they are set.
+type KeyRing struct {
+	repo          domain.SigningKeyRepository
+	configSecrets map[string][]byte
+
+	mu         sync.RWMutex
+	keys       map[string]domain.SigningKey
+	current    map[string]domain.SigningKey
+	lastReload time.Time
+	reloadErr  error
+}
+
+// NewKeyRing returns a key ring using only the configured secrets until
+// Reload is called. repo may be nil.
+func NewKeyRing(cfg config.Config, repo domain.SigningKeyRepository) *KeyRing {
+	return &KeyRing{
+		repo: repo,
+		configSecrets: map[string][]byte{
+			domain.KeyPurposeAccess:  []byte(cfg.JWTSecret),
+			domain.KeyPurposeRefresh: []byte(cfg.JWTRefreshSecret),
+		},
+		keys:    make(map[string]domain.SigningKey),
+		current: make(map[string]domain.SigningKey),
+	}
+}
+
+func (k *KeyRing) Reload(ctx context.Context) error {
+	if k.repo == nil {
+		return nil
+	}
+
+	keys, err := k.repo.List(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to load signing keys: %w", err)
+		k.mu.Lock()
+		k.reloadErr = err
+		k.mu.Unlock()
+		return err
+	}
+
+	byID := make(map[string]domain.SigningKey, len(keys))
+	current := make(map[string]domain.SigningKey)
+	for _, key := range keys {
+		byID[key.ID] = key
+		if cur, ok := current[key.Purpose]; !ok || key.CreatedAt.After(cur.CreatedAt) {
+			current[key.Purpose] = key
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for purpose, key := range current {
+		if prev := k.current[purpose].ID; prev != "" && prev != key.ID {
+			slog.InfoContext(ctx, "signing key rotated", "purpose", purpose, "kid", key.ID)
+		}
+	}
+	k.keys = byID
+	k.current = current
+	k.lastReload = time.Now()
+	k.reloadErr = nil
+	return nil
+}
+
+// Ready reports whether every purpose has a signing key and the last reload
+// succeeded. Until a failed reload is retried successfully, keys rotated in
+// the meantime are unknown to this instance.
+func (k *KeyRing) Ready() error {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.reloadErr != nil {
+		return k.reloadErr
+	}
+	for _, purpose := range []string{domain.KeyPurposeAccess, domain.KeyPurposeRefresh} {
+		if _, ok := k.current[purpose]; !ok && len(k.configSecrets[purpose]) == 0 {
+			return fmt.Errorf("no %s signing key", purpose)
+		}
+	}
+	return nil
+}
+
+// Run reloads the keys every interval until ctx is cancelled.
+func (k *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to reload signing keys", "error", err)
+			}
+		}
+	}
+}
+
+// SigningKey returns the key new tokens of the purpose are signed with. kid
+// is empty for the configured secret.
+func (k *KeyRing) SigningKey(purpose string) (kid string, secret []byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if key, ok := k.current[purpose]; ok {
+		return key.ID, key.Secret
+	}
+	return "", k.configSecrets[purpose]
+}
+
+// VerificationKey returns the secret for a token's kid, which must belong to
+// the given purpose.
+func (k *KeyRing) VerificationKey(purpose, kid string) ([]byte, error) {
+	if kid == "" {
+		return k.configSecrets[purpose], nil
+	}
+
+	key, ok := k.lookup(kid)
+	if !ok && k.missReloadDue() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := k.Reload(ctx); err != nil {
+			slog.WarnContext(ctx, "failed to reload signing keys", "error", err)
+		}
+		key, ok = k.lookup(kid)
+	}
+	if !ok || key.Purpose != purpose {
+		return nil, ErrUnknownSigningKey
+	}
+	return key.Secret, nil
+}
+
+func (k *KeyRing) lookup(kid string) (domain.SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func (k *KeyRing) missReloadDue() bool {
+	if k.repo == nil {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if time.Since(k.lastReload) < keyRingMissReload {
+		return false
+	}
+	k.lastReload = time.Now()
+	return true
+}
+
+// NewSigningKey generates a random key for the purpose.
+func NewSigningKey(purpose string) (*domain.SigningKey, error) {
+	if purpose != domain.KeyPurposeAccess && purpose != domain.KeyPurposeRefresh {
+		return nil, fmt.Errorf("unknown key purpose %q", purpose)
+	}
+
+	secret := make([]byte, signingKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &domain.SigningKey{
+		ID:        purpose + "-" + hex.EncodeToString(id),
+		Purpose:   purpose,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSigningKeyRepository struct {
+	keys []domain.SigningKey
+}
+
+func (r *fakeSigningKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *fakeSigningKeyRepository) List(ctx context.Context) ([]domain.SigningKey, error) {
+	return r.keys, nil
+}
+
+func (r *fakeSigningKeyRepository) Delete(ctx context.Context, id string) error {
+	for i, key := range r.keys {
+		if key.ID == id {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestTokenServiceKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		JWTSecret:        "access-secret",
+		JWTRefreshSecret: "refresh-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 24 * time.Hour,
+	}
+	repo := &fakeSigningKeyRepository{}
+	keys := service.NewKeyRing(cfg, repo)
+	require.NoError(t, keys.Reload(ctx))
+	tokens := service.NewTokenService(cfg, keys)
+	user := &domain.User{ID: 7, Email: "rotate@example.com", Role: domain.RoleUser}
+
+	fromConfig, err := tokens.GenerateAccessToken(user, "")
+	require.NoError(t, err)
+
+	rotate := func() *domain.SigningKey {
+		key, err := service.NewSigningKey(domain.KeyPurposeAccess)
+		require.NoError(t, err)
+		key.CreatedAt = time.Now().Add(time.Duration(len(repo.keys)) * time.Second)
+		require.NoError(t, repo.Create(ctx, key))
+		require.NoError(t, keys.Reload(ctx))
+		return key
+	}
+
+	first := rotate()
+	signedWithFirst, err := tokens.GenerateAccessToken(user, "")
+	require.NoError(t, err)
+	second := rotate()
+	signedWithSecond, err := tokens.GenerateAccessToken(user, "")
+	require.NoError(t, err)
+
+	kid, _ := keys.SigningKey(domain.KeyPurposeAccess)
+	assert.Equal(t, second.ID, kid)
+
+	for name, token := range map[string]string{"ConfigSecret": fromConfig, "PreviousKey": signedWithFirst, "CurrentKey": signedWithSecond} {
+		claims, err := tokens.ValidateToken(token, false)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, user.ID, claims.UserID, name)
+		}
+	}
+
+	t.Run("RetiredKey", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, first.ID))
+		require.NoError(t, keys.Reload(ctx))
+
+		_, err := tokens.ValidateToken(signedWithFirst, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("WrongPurpose", func(t *testing.T) {
+		_, err := tokens.ValidateToken(signedWithSecond, true)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
+
+type failingSigningKeyRepository struct {
+	fakeSigningKeyRepository
+}
+
+func (r *failingSigningKeyRepository) List(ctx context.Context) ([]domain.SigningKey, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestKeyRingReady(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ConfigSecrets", func(t *testing.T) {
+		keys := service.NewKeyRing(config.Config{JWTSecret: "a", JWTRefreshSecret: "r"}, &fakeSigningKeyRepository{})
+		require.NoError(t, keys.Reload(ctx))
+		assert.NoError(t, keys.Ready())
+	})
+
+	t.Run("MissingRefreshKey", func(t *testing.T) {
+		keys := service.NewKeyRing(config.Config{JWTSecret: "a"}, &fakeSigningKeyRepository{})
+		require.NoError(t, keys.Reload(ctx))
+		assert.EqualError(t, keys.Ready(), "no refresh signing key")
+	})
+
+	t.Run("FailedReload", func(t *testing.T) {
+		keys := service.NewKeyRing(config.Config{JWTSecret: "a", JWTRefreshSecret: "r"}, &failingSigningKeyRepository{})
+		assert.Error(t, keys.Reload(ctx))
+		assert.ErrorContains(t, keys.Ready(), "connection refused")
+	})
+}
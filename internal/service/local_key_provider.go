@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const dataKeyLength = 32
+
+// LocalKeyProvider is a file-based stand-in for a KMS. It wraps data keys
+// with AES-256-GCM under versioned key encryption keys read from a keyring
+// file; the highest version wraps new data keys, older ones still unwrap the
+// data keys they wrapped.
+type LocalKeyProvider struct {
+	keys    map[uint32]cipher.AEAD
+	current uint32
+}
+
+// NewLocalKeyProvider reads "<version>:<base64 key>" lines of 32-byte keys.
+func NewLocalKeyProvider(path string) (*LocalKeyProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ENCRYPTION_LOCAL_KEYRING: %w", err)
+	}
+	defer f.Close()
+
+	p := &LocalKeyProvider{keys: make(map[uint32]cipher.AEAD)}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Errors name the line, never its content: a malformed line may
+		// well be a key.
+		versionStr, encoded, ok := strings.Cut(line, ":")
+		version, err := strconv.ParseUint(versionStr, 10, 32)
+		if !ok || err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid ENCRYPTION_LOCAL_KEYRING line %d: want <version>:<base64 key> with a positive version", lineNo)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != dataKeyLength {
+			return nil, fmt.Errorf("keyring version %d is not a base64-encoded %d-byte key", version, dataKeyLength)
+		}
+		if _, dup := p.keys[uint32(version)]; dup {
+			return nil, fmt.Errorf("duplicate keyring version %d", version)
+		}
+
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[uint32(version)] = aead
+		p.current = max(p.current, uint32(version))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.current == 0 {
+		return nil, errors.New("ENCRYPTION_LOCAL_KEYRING contains no keys")
+	}
+	return p, nil
+}
+
+// GenerateDataKey wraps the key as "<version><nonce><sealed key>", with the
+// big-endian version authenticated as additional data.
+func (p *LocalKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	key := make([]byte, dataKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+
+	aead := p.keys[p.current]
+	wrapped := binary.BigEndian.AppendUint32(nil, p.current)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	wrapped = append(wrapped, nonce...)
+	wrapped = aead.Seal(wrapped, nonce, key, wrapped[:4])
+	return key, wrapped, nil
+}
+
+func (p *LocalKeyProvider) UnwrapDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 4 {
+		return nil, errors.New("malformed wrapped data key")
+	}
+	version := binary.BigEndian.Uint32(wrapped)
+	aead, ok := p.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("data key was wrapped with keyring version %d, which is not in the keyring", version)
+	}
+	if len(wrapped) < 4+aead.NonceSize() {
+		return nil, errors.New("malformed wrapped data key")
+	}
+
+	nonce := wrapped[4 : 4+aead.NonceSize()]
+	key, err := aead.Open(nil, nonce, wrapped[4+aead.NonceSize():], wrapped[:4])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
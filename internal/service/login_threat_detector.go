@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-auth-service/config"
+	constant "go-auth-service/internal/constants"
+	"go-auth-service/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginThreatDetector keeps sliding windows of failed logins in Redis sorted
+// sets, keyed by source IP, source network, source AS (with an ASN table) and
+// password fingerprint. Each
+// window holds the distinct accounts targeted, so a single source hitting many
+// emails (stuffing) or one password tried against many emails (spraying)
+// trips a threshold even though no individual account sees many failures.
+type LoginThreatDetector struct {
+	redisClient       redis.UniversalClient
+	keyPrefix         string
+	fingerprintKey    []byte
+	window            time.Duration
+	blockDuration     time.Duration
+	ipThreshold       int
+	cidrThreshold     int
+	asnThreshold      int
+	passwordThreshold int
+	ipv4Prefix        int
+	ipv6Prefix        int
+	asns              *ASNTable
+	action            domain.ThreatAction
+}
+
+func NewLoginThreatDetector(cfg config.Config, redisClient redis.UniversalClient) (*LoginThreatDetector, error) {
+	action := domain.ThreatAction(strings.ToLower(cfg.LoginThreatAction))
+	switch action {
+	case domain.ThreatActionAlert, domain.ThreatActionChallenge, domain.ThreatActionBlock:
+	default:
+		return nil, fmt.Errorf("invalid LOGIN_THREAT_ACTION: %q", cfg.LoginThreatAction)
+	}
+
+	var asns *ASNTable
+	if cfg.LoginThreatASNFile != "" {
+		var err error
+		if asns, err = LoadASNTable(cfg.LoginThreatASNFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Attempted passwords are only ever stored as a keyed fingerprint so the
+	// Redis keyspace cannot be used as a dictionary of what attackers tried.
+	mac := hmac.New(sha256.New, []byte(cfg.JWTSecret))
+	mac.Write([]byte("login-threat-fingerprint"))
+
+	return &LoginThreatDetector{
+		redisClient:       redisClient,
+		keyPrefix:         cfg.RedisKeyPrefix + constant.STR_LOGIN_THREAT,
+		fingerprintKey:    mac.Sum(nil),
+		window:            cfg.LoginThreatWindow,
+		blockDuration:     cfg.LoginThreatBlockDuration,
+		ipThreshold:       cfg.LoginThreatIPThreshold,
+		cidrThreshold:     cfg.LoginThreatCIDRThreshold,
+		asnThreshold:      cfg.LoginThreatASNThreshold,
+		passwordThreshold: cfg.LoginThreatPasswordThreshold,
+		ipv4Prefix:        cfg.LoginThreatIPv4Prefix,
+		ipv6Prefix:        cfg.LoginThreatIPv6Prefix,
+		asns:              asns,
+		action:            action,
+	}, nil
+}
+
+// Check reports whether the source is currently flagged.
+func (d *LoginThreatDetector) Check(ctx context.Context, ip string) (domain.ThreatVerdict, error) {
+	scopes := d.sourceScopes(ip)
+	if len(scopes) == 0 {
+		return domain.ThreatVerdict{}, nil
+	}
+
+	// One GET per key rather than MGET, which fails in cluster mode when the
+	// keys hash to different slots.
+	cmds := make([]*redis.StringCmd, len(scopes))
+	_, err := d.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, s := range scopes {
+			cmds[i] = pipe.Get(ctx, d.flagKey(s.scope, s.value))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return domain.ThreatVerdict{}, err
+	}
+
+	for i, cmd := range cmds {
+		reason, err := cmd.Result()
+		if errors.Is(err, redis.Nil) || (err == nil && reason == "") {
+			continue
+		}
+		if err != nil {
+			return domain.ThreatVerdict{}, err
+		}
+		return domain.ThreatVerdict{
+			Action: d.action,
+			Scope:  scopes[i].scope,
+			Source: scopes[i].value,
+			Reason: reason,
+		}, nil
+	}
+
+	return domain.ThreatVerdict{}, nil
+}
+
+// RecordFailure adds a failed attempt to every window it belongs to and flags
+// the source if any window crosses its threshold.
+func (d *LoginThreatDetector) RecordFailure(ctx context.Context, attempt domain.LoginAttempt) (domain.ThreatVerdict, error) {
+	now := time.Now()
+	member := d.hash(strings.ToLower(strings.TrimSpace(attempt.Email)))
+	minScore := strconv.FormatInt(now.Add(-d.window).UnixMilli(), 10)
+
+	type window struct {
+		scope     string
+		value     string
+		threshold int
+		card      *redis.IntCmd
+	}
+
+	var windows []*window
+	for _, s := range d.sourceScopes(attempt.IP) {
+		threshold := d.ipThreshold
+		switch s.scope {
+		case "cidr":
+			threshold = d.cidrThreshold
+		case "asn":
+			threshold = d.asnThreshold
+		}
+		windows = append(windows, &window{scope: s.scope, value: s.value, threshold: threshold})
+	}
+	if attempt.Password != "" {
+		windows = append(windows, &window{scope: "password", value: d.hash(attempt.Password), threshold: d.passwordThreshold})
+	}
+
+	_, err := d.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, w := range windows {
+			key := d.keyPrefix + w.scope + ":" + w.value
+			pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+			pipe.ZRemRangeByScore(ctx, key, "-inf", "("+minScore)
+			w.card = pipe.ZCard(ctx, key)
+			pipe.Expire(ctx, key, d.window)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.ThreatVerdict{}, err
+	}
+
+	for _, w := range windows {
+		if w.threshold <= 0 || int(w.card.Val()) < w.threshold {
+			continue
+		}
+
+		reason := fmt.Sprintf("%d distinct accounts failed from %s within %s", w.card.Val(), w.scope, d.window)
+		if w.scope == "password" {
+			// A sprayed password is not a source we can block on its own,
+			// so the attempt's IP carries the flag.
+			reason = fmt.Sprintf("same password failed against %d distinct accounts within %s", w.card.Val(), d.window)
+			w.scope, w.value = "ip", attempt.IP
+			if w.value == "" {
+				continue
+			}
+		}
+
+		newlyFlagged, err := d.redisClient.SetNX(ctx, d.flagKey(w.scope, w.value), reason, d.blockDuration).Result()
+		if err != nil {
+			return domain.ThreatVerdict{}, err
+		}
+
+		return domain.ThreatVerdict{
+			Action:       d.action,
+			Scope:        w.scope,
+			Source:       w.value,
+			Reason:       reason,
+			NewlyFlagged: newlyFlagged,
+		}, nil
+	}
+
+	return domain.ThreatVerdict{}, nil
+}
+
+type sourceScope struct {
+	scope string
+	value string
+}
+
+func (d *LoginThreatDetector) sourceScopes(ip string) []sourceScope {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil
+	}
+
+	scopes := []sourceScope{{scope: "ip", value: parsed.String()}}
+
+	var network *net.IPNet
+	if v4 := parsed.To4(); v4 != nil {
+		network = &net.IPNet{IP: v4.Mask(net.CIDRMask(d.ipv4Prefix, 32)), Mask: net.CIDRMask(d.ipv4Prefix, 32)}
+	} else {
+		network = &net.IPNet{IP: parsed.Mask(net.CIDRMask(d.ipv6Prefix, 128)), Mask: net.CIDRMask(d.ipv6Prefix, 128)}
+	}
+
+	scopes = append(scopes, sourceScope{scope: "cidr", value: network.String()})
+
+	if d.asns != nil {
+		addr, _ := netip.AddrFromSlice(parsed)
+		if asn, ok := d.asns.Lookup(addr); ok {
+			scopes = append(scopes, sourceScope{scope: "asn", value: "AS" + strconv.FormatUint(uint64(asn), 10)})
+		}
+	}
+	return scopes
+}
+
+func (d *LoginThreatDetector) hash(value string) string {
+	mac := hmac.New(sha256.New, d.fingerprintKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (d *LoginThreatDetector) flagKey(scope, value string) string {
+	return d.keyPrefix + "flag:" + scope + ":" + value
+}
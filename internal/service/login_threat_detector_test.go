@@ -0,0 +1,216 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// asnFile lists 203.0.113.0/24 and 2001:db8::/32 under AS64500 and
+// 198.51.100.0/24 under AS64501, in the iptoasn.com layout.
+func asnFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "ip2asn.tsv")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"0.0.0.0\t0.255.255.255\t0\tNone\tNot routed\n"+
+			"198.51.100.0\t198.51.100.255\t64501\tZZ\tEXAMPLE-B\n"+
+			"203.0.113.0\t203.0.113.255\t64500\tZZ\tEXAMPLE-A\n"+
+			"2001:db8::\t2001:db8:ffff:ffff:ffff:ffff:ffff:ffff\t64500\tZZ\tEXAMPLE-A\n",
+	), 0o600))
+	return path
+}
+
+func TestLoginThreatDetector(t *testing.T) {
+	ctx := context.Background()
+	base := config.Config{
+		JWTSecret:                    "0123456789abcdef0123456789abcdef",
+		LoginThreatWindow:            10 * time.Minute,
+		LoginThreatIPThreshold:       3,
+		LoginThreatCIDRThreshold:     5,
+		LoginThreatASNThreshold:      6,
+		LoginThreatPasswordThreshold: 4,
+		LoginThreatIPv4Prefix:        24,
+		LoginThreatIPv6Prefix:        48,
+		LoginThreatAction:            "block",
+		LoginThreatBlockDuration:     15 * time.Minute,
+	}
+	newDetector := func(t *testing.T, cfg config.Config) *service.LoginThreatDetector {
+		mr := miniredis.RunT(t)
+		detector, err := service.NewLoginThreatDetector(cfg, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+		require.NoError(t, err)
+		return detector
+	}
+	// fail records failed logins from ip against n distinct accounts with
+	// distinct passwords and returns the last verdict.
+	fail := func(t *testing.T, d *service.LoginThreatDetector, ip string, n int) domain.ThreatVerdict {
+		var verdict domain.ThreatVerdict
+		for i := 0; i < n; i++ {
+			var err error
+			verdict, err = d.RecordFailure(ctx, domain.LoginAttempt{
+				Email:    fmt.Sprintf("user%d-%s@example.com", i, ip),
+				Password: fmt.Sprintf("password-%d-%s", i, ip),
+				IP:       ip,
+			})
+			require.NoError(t, err)
+		}
+		return verdict
+	}
+	flagged := func(t *testing.T, d *service.LoginThreatDetector, ip string) domain.ThreatVerdict {
+		verdict, err := d.Check(ctx, ip)
+		require.NoError(t, err)
+		return verdict
+	}
+
+	t.Run("IP", func(t *testing.T) {
+		d := newDetector(t, base)
+
+		assert.Equal(t, domain.ThreatActionNone, fail(t, d, "203.0.113.7", 2).Action)
+		verdict := fail(t, d, "203.0.113.7", 3)
+
+		assert.Equal(t, domain.ThreatActionBlock, verdict.Action)
+		assert.Equal(t, "ip", verdict.Scope)
+		assert.Equal(t, "203.0.113.7", verdict.Source)
+		assert.True(t, verdict.NewlyFlagged)
+		assert.Equal(t, domain.ThreatActionBlock, flagged(t, d, "203.0.113.7").Action)
+		assert.Equal(t, domain.ThreatActionNone, flagged(t, d, "203.0.113.8").Action, "the network is below its threshold")
+	})
+
+	t.Run("SameAccountCountsOnce", func(t *testing.T) {
+		d := newDetector(t, base)
+
+		for i := 0; i < 10; i++ {
+			verdict, err := d.RecordFailure(ctx, domain.LoginAttempt{Email: "victim@example.com", Password: fmt.Sprint(i), IP: "203.0.113.7"})
+			require.NoError(t, err)
+			assert.Equal(t, domain.ThreatActionNone, verdict.Action)
+		}
+	})
+
+	t.Run("CIDR", func(t *testing.T) {
+		d := newDetector(t, base)
+
+		var verdict domain.ThreatVerdict
+		for i := 1; i <= 5; i++ {
+			verdict = fail(t, d, fmt.Sprintf("203.0.113.%d", i), 1)
+		}
+
+		assert.Equal(t, "cidr", verdict.Scope)
+		assert.Equal(t, "203.0.113.0/24", verdict.Source)
+		assert.Equal(t, domain.ThreatActionBlock, flagged(t, d, "203.0.113.200").Action)
+		assert.Equal(t, domain.ThreatActionNone, flagged(t, d, "203.0.114.1").Action)
+	})
+
+	t.Run("IPv6Network", func(t *testing.T) {
+		d := newDetector(t, base)
+
+		var verdict domain.ThreatVerdict
+		for i := 1; i <= 5; i++ {
+			verdict = fail(t, d, fmt.Sprintf("2001:db8:1:%d::1", i), 1)
+		}
+
+		assert.Equal(t, "cidr", verdict.Scope)
+		assert.Equal(t, "2001:db8:1::/48", verdict.Source)
+	})
+
+	t.Run("ASN", func(t *testing.T) {
+		cfg := base
+		cfg.LoginThreatASNFile = asnFile(t)
+		cfg.LoginThreatCIDRThreshold = 100
+		d := newDetector(t, cfg)
+
+		// Spread over two networks of the same AS so no network trips.
+		var verdict domain.ThreatVerdict
+		for i := 1; i <= 3; i++ {
+			fail(t, d, fmt.Sprintf("203.0.113.%d", i), 1)
+			verdict = fail(t, d, fmt.Sprintf("2001:db8:%d::1", i), 1)
+		}
+
+		assert.Equal(t, "asn", verdict.Scope)
+		assert.Equal(t, "AS64500", verdict.Source)
+		assert.Equal(t, domain.ThreatActionBlock, flagged(t, d, "203.0.113.99").Action)
+		assert.Equal(t, domain.ThreatActionNone, flagged(t, d, "198.51.100.1").Action, "other AS")
+	})
+
+	t.Run("PasswordSpraying", func(t *testing.T) {
+		d := newDetector(t, base)
+
+		var verdict domain.ThreatVerdict
+		for i := 1; i <= 4; i++ {
+			var err error
+			verdict, err = d.RecordFailure(ctx, domain.LoginAttempt{
+				Email:    fmt.Sprintf("user%d@example.com", i),
+				Password: "Summer2024!",
+				IP:       fmt.Sprintf("198.51.%d.1", i),
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, "ip", verdict.Scope)
+		assert.Equal(t, "198.51.4.1", verdict.Source, "the IP trying the sprayed password is flagged")
+		assert.Contains(t, verdict.Reason, "same password")
+	})
+
+	t.Run("SlidingWindow", func(t *testing.T) {
+		cfg := base
+		cfg.LoginThreatWindow = 200 * time.Millisecond
+		d := newDetector(t, cfg)
+
+		fail(t, d, "203.0.113.7", 2)
+		time.Sleep(250 * time.Millisecond)
+		verdict := fail(t, d, "203.0.113.7", 1)
+
+		assert.Equal(t, domain.ThreatActionNone, verdict.Action, "earlier failures left the window")
+	})
+
+	t.Run("AlertOnly", func(t *testing.T) {
+		cfg := base
+		cfg.LoginThreatAction = "alert"
+		d := newDetector(t, cfg)
+
+		verdict := fail(t, d, "203.0.113.7", 3)
+
+		assert.Equal(t, domain.ThreatActionAlert, verdict.Action)
+		assert.True(t, verdict.NewlyFlagged)
+		assert.False(t, fail(t, d, "203.0.113.7", 3).NewlyFlagged, "flagged once per block duration")
+	})
+}
+
+func TestASNTable(t *testing.T) {
+	table, err := service.LoadASNTable(asnFile(t))
+	require.NoError(t, err)
+
+	for ip, want := range map[string]uint32{
+		"203.0.113.0":         64500,
+		"203.0.113.255":       64500,
+		"198.51.100.7":        64501,
+		"::ffff:198.51.100.7": 64501,
+		"2001:db8:1::1":       64500,
+		"0.1.2.3":             0,
+		"192.0.2.1":           0,
+		"2001:db9::1":         0,
+	} {
+		asn, ok := table.Lookup(netip.MustParseAddr(ip))
+		assert.Equal(t, want != 0, ok, ip)
+		assert.Equal(t, want, asn, ip)
+	}
+
+	t.Run("InvalidLine", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ip2asn.tsv")
+		require.NoError(t, os.WriteFile(path, []byte("# ranges\n203.0.113.0\t203.0.113.255\t64500\n203.0.113.9\t203.0.113.1\t64500\n"), 0o600))
+
+		_, err := service.LoadASNTable(path)
+
+		assert.ErrorContains(t, err, "line 3")
+	})
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+)
+
+const outboxMaxBackoff = time.Hour
+
+// OutboxRelay drains the outbox to every configured sink. An event is marked
+// published only after all sinks accepted it, which gives at-least-once
+// delivery: a sink may see an event again if a later sink failed.
+//
+// Events are relayed oldest first, but an event that fails is retried with
+// exponential backoff while the ones after it go ahead, so one bad event
+// cannot hold up the outbox. After OUTBOX_MAX_ATTEMPTS failures it is
+// dead-lettered and left for an operator.
+type OutboxRelay struct {
+	repo        domain.OutboxRepository
+	sinks       []domain.EventSink
+	interval    time.Duration
+	batchSize   int
+	lease       time.Duration
+	backoffBase time.Duration
+	maxAttempts int
+}
+
+func NewOutboxRelay(cfg config.Config, repo domain.OutboxRepository, sinks []domain.EventSink) *OutboxRelay {
+	return &OutboxRelay{
+		repo:        repo,
+		sinks:       sinks,
+		interval:    cfg.OutboxPollInterval,
+		batchSize:   cfg.OutboxBatchSize,
+		lease:       cfg.OutboxLease,
+		backoffBase: cfg.OutboxBackoffBase,
+		maxAttempts: cfg.OutboxMaxAttempts,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back so a backlog clears
+		// without waiting a tick per batch.
+		for {
+			n, err := r.RelayBatch(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "outbox relay failed", "error", err)
+			}
+			if err != nil || n < r.batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch claims a batch of due events and relays them, returning how many
+// it claimed. Failed events are rescheduled rather than reported.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	start := time.Now()
+	events, err := r.repo.ClaimDueEvents(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		// Once the lease lapses another relay may claim the rest; leave them.
+		if ctx.Err() != nil || time.Since(start) >= r.lease {
+			break
+		}
+		r.relay(ctx, &events[i])
+	}
+	return len(events), nil
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, row *domain.OutboxEvent) {
+	err := r.send(ctx, row)
+
+	row.Attempts++
+	row.LastError = ""
+
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		row.PublishedAt = &now
+	case row.Attempts >= r.maxAttempts:
+		row.DeadAt = &now
+		row.LastError = truncate(err.Error(), 1024)
+		slog.ErrorContext(ctx, "outbox event dead-lettered", "event_id", row.IdempotencyKey, "type", row.EventType, "attempts", row.Attempts, "error", err)
+	default:
+		row.LastError = truncate(err.Error(), 1024)
+		row.NextAttemptAt = now.Add(r.backoff(row.Attempts))
+		slog.WarnContext(ctx, "outbox event relay failed", "event_id", row.IdempotencyKey, "type", row.EventType, "attempts", row.Attempts, "error", err)
+	}
+
+	if err := r.repo.UpdateEvent(ctx, row); err != nil {
+		slog.WarnContext(ctx, "failed to update outbox event", "event_id", row.IdempotencyKey, "error", err)
+	}
+}
+
+func (r *OutboxRelay) send(ctx context.Context, row *domain.OutboxEvent) error {
+	event, err := row.DomainEvent()
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	for _, sink := range r.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := float64(r.backoffBase) * math.Pow(2, float64(attempts-1))
+	if delay > float64(outboxMaxBackoff) {
+		return outboxMaxBackoff
+	}
+	return time.Duration(delay)
+}
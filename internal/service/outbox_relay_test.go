@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recordingSink accepts every event except those of the types in reject.
+type recordingSink struct {
+	reject map[string]bool
+	sent   []string
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(ctx context.Context, event domain.DomainEvent) error {
+	if s.reject[event.Type] {
+		return errors.New("rejected")
+	}
+	s.sent = append(s.sent, event.Type)
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		OutboxPollInterval: time.Second,
+		OutboxBatchSize:    10,
+		OutboxLease:        time.Minute,
+		OutboxBackoffBase:  time.Minute,
+		OutboxMaxAttempts:  2,
+	}
+	setup := func(t *testing.T) (*gorm.DB, domain.OutboxRepository) {
+		db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+		require.NoError(t, err)
+		return db, repository.NewOutboxRepository(db)
+	}
+	// due makes every scheduled retry due now.
+	due := func(t *testing.T, db *gorm.DB) {
+		require.NoError(t, db.Model(&domain.OutboxEvent{}).Where("1 = 1").Update("next_attempt_at", time.Now().UTC().Add(-time.Second)).Error)
+	}
+	row := func(t *testing.T, db *gorm.DB, eventType string) domain.OutboxEvent {
+		var event domain.OutboxEvent
+		require.NoError(t, db.Where("event_type = ?", eventType).First(&event).Error)
+		return event
+	}
+
+	t.Run("Publishes", func(t *testing.T) {
+		db, repo := setup(t)
+		sink := &recordingSink{}
+		relay := service.NewOutboxRelay(cfg, repo, []domain.EventSink{sink})
+		require.NoError(t, repo.Publish(ctx, domain.NewDomainEvent(domain.EventUserRegistered, 1, nil)))
+		require.NoError(t, repo.Publish(ctx, domain.NewDomainEvent(domain.EventUserUpdated, 1, nil)))
+
+		n, err := relay.RelayBatch(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{domain.EventUserRegistered, domain.EventUserUpdated}, sink.sent)
+		assert.NotNil(t, row(t, db, domain.EventUserUpdated).PublishedAt)
+
+		n, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "published events are not claimed again")
+	})
+
+	t.Run("PoisonEvent", func(t *testing.T) {
+		db, repo := setup(t)
+		sink := &recordingSink{reject: map[string]bool{domain.EventUserRegistered: true}}
+		relay := service.NewOutboxRelay(cfg, repo, []domain.EventSink{sink})
+		require.NoError(t, repo.Publish(ctx, domain.NewDomainEvent(domain.EventUserRegistered, 1, nil)))
+		require.NoError(t, repo.Publish(ctx, domain.NewDomainEvent(domain.EventUserUpdated, 1, nil)))
+
+		_, err := relay.RelayBatch(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{domain.EventUserUpdated}, sink.sent, "later events are not held up")
+		failed := row(t, db, domain.EventUserRegistered)
+		assert.Nil(t, failed.PublishedAt)
+		assert.Equal(t, 1, failed.Attempts)
+		assert.Equal(t, "recording sink: rejected", failed.LastError)
+		assert.True(t, failed.NextAttemptAt.After(time.Now().Add(30*time.Second)), "retried with backoff")
+
+		n, err := relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "not due yet")
+
+		due(t, db)
+		_, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.NotNil(t, row(t, db, domain.EventUserRegistered).DeadAt, "dead-lettered after the last attempt")
+
+		due(t, db)
+		n, err = relay.RelayBatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "dead events are not claimed")
+	})
+
+	t.Run("InvalidPayload", func(t *testing.T) {
+		db, repo := setup(t)
+		relay := service.NewOutboxRelay(cfg, repo, []domain.EventSink{&recordingSink{}})
+		require.NoError(t, db.Create(&domain.OutboxEvent{
+			IdempotencyKey: "broken",
+			EventType:      domain.EventUserUpdated,
+			Payload:        "{",
+			CreatedAt:      time.Now().UTC(),
+			NextAttemptAt:  time.Now().UTC(),
+		}).Error)
+
+		_, err := relay.RelayBatch(ctx)
+
+		require.NoError(t, err)
+		assert.Contains(t, row(t, db, domain.EventUserUpdated).LastError, "invalid payload")
+	})
+}
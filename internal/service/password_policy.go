@@ -0,0 +1,290 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+)
+
+// commonPasswords is a small built-in deny list; PASSWORD_BANNED_FILE extends it.
+var commonPasswords = []string{
+	"password", "passw0rd", "123456", "12345678", "123456789", "1234567890",
+	"qwerty", "qwertyuiop", "abc123", "111111", "123123", "letmein",
+	"welcome", "monkey", "dragon", "iloveyou", "admin", "login", "master",
+	"sunshine", "princess", "football", "baseball", "superman", "trustno1",
+	"changeme", "secret", "whatever", "starwars", "shadow",
+}
+
+// PasswordPolicy runs every configured rule and collects all violations so
+// the user can fix them in one go.
+type PasswordPolicy struct {
+	rules []domain.PasswordRule
+}
+
+// NewPasswordPolicy builds the rules enabled in cfg; extra rules (such as a
+// breached password check) are appended after them.
+func NewPasswordPolicy(cfg config.Config, extra ...domain.PasswordRule) (*PasswordPolicy, error) {
+	rules := []domain.PasswordRule{
+		&lengthRule{min: cfg.PasswordMinLength, max: cfg.PasswordMaxLength},
+		&characterClassRule{
+			upper:  cfg.PasswordRequireUppercase,
+			lower:  cfg.PasswordRequireLowercase,
+			digit:  cfg.PasswordRequireDigit,
+			symbol: cfg.PasswordRequireSymbol,
+		},
+	}
+
+	banned, err := loadBannedPasswords(cfg.PasswordBannedFile)
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, &bannedRule{banned: banned})
+
+	if cfg.PasswordCheckSimilarity {
+		rules = append(rules, &similarityRule{})
+	}
+	if cfg.PasswordMinStrength > 0 {
+		rules = append(rules, &strengthRule{minScore: cfg.PasswordMinStrength})
+	}
+
+	return &PasswordPolicy{rules: append(rules, extra...)}, nil
+}
+
+func (p *PasswordPolicy) Validate(ctx context.Context, candidate domain.PasswordCandidate) error {
+	var violations []domain.PolicyViolation
+	for _, rule := range p.rules {
+		v, err := rule.Check(ctx, candidate)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, v...)
+	}
+
+	if len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func loadBannedPasswords(path string) (map[string]struct{}, error) {
+	banned := make(map[string]struct{}, len(commonPasswords))
+	for _, p := range commonPasswords {
+		banned[p] = struct{}{}
+	}
+
+	if path == "" {
+		return banned, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PASSWORD_BANNED_FILE: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.ToLower(strings.TrimSpace(scanner.Text())); line != "" {
+			banned[line] = struct{}{}
+		}
+	}
+	return banned, scanner.Err()
+}
+
+type lengthRule struct {
+	min int
+	max int
+}
+
+// Check counts characters rather than bytes so non-ASCII passwords are not penalised.
+func (r *lengthRule) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	n := utf8.RuneCountInString(c.Password)
+	switch {
+	case n < r.min:
+		return []domain.PolicyViolation{{
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters long", r.min),
+			Params:  map[string]any{"min": r.min},
+		}}, nil
+	case r.max > 0 && n > r.max:
+		return []domain.PolicyViolation{{
+			Rule:    "max_length",
+			Message: fmt.Sprintf("Password must be at most %d characters long", r.max),
+			Params:  map[string]any{"max": r.max},
+		}}, nil
+	}
+	return nil, nil
+}
+
+type characterClassRule struct {
+	upper, lower, digit, symbol bool
+}
+
+func (r *characterClassRule) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, ch := range c.Password {
+		switch {
+		case unicode.IsUpper(ch):
+			hasUpper = true
+		case unicode.IsLower(ch):
+			hasLower = true
+		case unicode.IsDigit(ch):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	var violations []domain.PolicyViolation
+	for _, class := range []struct {
+		required, present bool
+		rule, message     string
+	}{
+		{r.upper, hasUpper, "require_uppercase", "Password must contain an uppercase letter"},
+		{r.lower, hasLower, "require_lowercase", "Password must contain a lowercase letter"},
+		{r.digit, hasDigit, "require_digit", "Password must contain a digit"},
+		{r.symbol, hasSymbol, "require_symbol", "Password must contain a symbol"},
+	} {
+		if class.required && !class.present {
+			violations = append(violations, domain.PolicyViolation{Rule: class.rule, Message: class.message})
+		}
+	}
+	return violations, nil
+}
+
+type bannedRule struct {
+	banned map[string]struct{}
+}
+
+// Check also matches the password with trailing digits and symbols removed,
+// so "Password123!" is caught by "password".
+func (r *bannedRule) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	lower := strings.ToLower(c.Password)
+	base := strings.TrimRightFunc(lower, func(ch rune) bool { return !unicode.IsLetter(ch) })
+
+	for _, candidate := range []string{lower, base} {
+		if _, ok := r.banned[candidate]; ok {
+			return []domain.PolicyViolation{{
+				Rule:    "banned",
+				Message: "Password is too common",
+			}}, nil
+		}
+	}
+	return nil, nil
+}
+
+type similarityRule struct{}
+
+// Check rejects passwords containing the user's email, its local part or any
+// part of their name of three or more characters.
+func (r *similarityRule) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	password := strings.ToLower(c.Password)
+
+	var tokens []string
+	if email := strings.ToLower(c.Email); email != "" {
+		tokens = append(tokens, email)
+		if local, _, ok := strings.Cut(email, "@"); ok {
+			tokens = append(tokens, local)
+		}
+	}
+	tokens = append(tokens, strings.Fields(strings.ToLower(c.Name))...)
+
+	for _, token := range tokens {
+		if utf8.RuneCountInString(token) >= 3 && strings.Contains(password, token) {
+			return []domain.PolicyViolation{{
+				Rule:    "similar_to_user",
+				Message: "Password must not contain your name or email address",
+			}}, nil
+		}
+	}
+	return nil, nil
+}
+
+type strengthRule struct {
+	minScore int
+}
+
+func (r *strengthRule) Check(ctx context.Context, c domain.PasswordCandidate) ([]domain.PolicyViolation, error) {
+	bits, score := EstimatePasswordStrength(c.Password)
+	if score >= r.minScore {
+		return nil, nil
+	}
+
+	return []domain.PolicyViolation{{
+		Rule:    "too_weak",
+		Message: "Password is too easy to guess; use a longer passphrase or more varied characters",
+		Params: map[string]any{
+			"score":        score,
+			"min_score":    r.minScore,
+			"entropy_bits": math.Round(bits*10) / 10,
+		},
+	}}, nil
+}
+
+// strengthThresholds are the entropy (in bits) needed for scores 1 to 4.
+var strengthThresholds = []float64{28, 36, 60, 128}
+
+// EstimatePasswordStrength returns an entropy estimate in bits and a score
+// from 0 (trivial) to 4 (very strong). The estimate is the size of the
+// character pool in use times the password length, where characters that
+// repeat or continue a sequence ("aaaa", "1234", "cba") count for very little.
+func EstimatePasswordStrength(password string) (float64, int) {
+	var lower, upper, digit, symbol, other bool
+	for _, ch := range password {
+		switch {
+		case ch > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(ch):
+			lower = true
+		case unicode.IsUpper(ch):
+			upper = true
+		case unicode.IsDigit(ch):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0, 0
+	}
+
+	effective := 0.0
+	var prev rune
+	for i, ch := range []rune(password) {
+		switch {
+		case i > 0 && (ch == prev || ch == prev+1 || ch == prev-1):
+			effective += 0.25
+		default:
+			effective++
+		}
+		prev = ch
+	}
+
+	bits := effective * math.Log2(float64(pool))
+
+	score := 0
+	for _, threshold := range strengthThresholds {
+		if bits >= threshold {
+			score++
+		}
+	}
+	return bits, score
+}
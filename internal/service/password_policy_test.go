@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicyConfig() config.Config {
+	return config.Config{
+		PasswordMinLength:       8,
+		PasswordMaxLength:       64,
+		PasswordCheckSimilarity: true,
+		PasswordMinStrength:     2,
+	}
+}
+
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+
+	var policyErr *domain.PasswordPolicyError
+	require.ErrorAs(t, err, &policyErr)
+
+	rules := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	policy, err := service.NewPasswordPolicy(testPolicyConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		candidate domain.PasswordCandidate
+		want      []string
+	}{
+		{"StrongPassphrase", domain.PasswordCandidate{Password: "violet-ferry-lantern-92"}, nil},
+		{"TooShort", domain.PasswordCandidate{Password: "a"}, []string{"min_length", "too_weak"}},
+		{"TooLong", domain.PasswordCandidate{Password: string(make([]byte, 65))}, []string{"max_length"}},
+		{"Banned", domain.PasswordCandidate{Password: "Password123!"}, []string{"banned"}},
+		{"Sequence", domain.PasswordCandidate{Password: "abcdefgh1234"}, []string{"too_weak"}},
+		{"ContainsEmail", domain.PasswordCandidate{Password: "jdoe-violet-ferry", Email: "jdoe@example.com"}, []string{"similar_to_user"}},
+		{"ContainsName", domain.PasswordCandidate{Password: "violet-margaret-9", Name: "Margaret Smith"}, []string{"similar_to_user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(ctx, tt.candidate)
+			assert.Equal(t, tt.want, violatedRules(t, err))
+		})
+	}
+
+	t.Run("CharacterClasses", func(t *testing.T) {
+		cfg := testPolicyConfig()
+		cfg.PasswordRequireUppercase = true
+		cfg.PasswordRequireDigit = true
+		cfg.PasswordRequireSymbol = true
+		policy, err := service.NewPasswordPolicy(cfg)
+		require.NoError(t, err)
+
+		err = policy.Validate(ctx, domain.PasswordCandidate{Password: "violetferrylantern"})
+
+		assert.Equal(t, []string{"require_uppercase", "require_digit", "require_symbol"}, violatedRules(t, err))
+	})
+}
+
+func TestEstimatePasswordStrength(t *testing.T) {
+	_, weak := service.EstimatePasswordStrength("aaaaaaaa")
+	_, strong := service.EstimatePasswordStrength("Violet-Ferry-Lantern-92")
+
+	assert.Equal(t, 0, weak)
+	assert.GreaterOrEqual(t, strong, 3)
+}
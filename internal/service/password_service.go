@@ -1,20 +1,363 @@
 package service
 
 import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go-auth-service/config"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type PasswordService struct{}
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+
+	pepperPrefix       = "$pepper$v="
+	minPepperKeyLength = 16
+
+	// bcrypt rejects longer passwords; they are hashed as "$sha256$<bcrypt
+	// hash of the base64 SHA-256 of the password>" instead.
+	bcryptMaxPasswordLength = 72
+	prehashPrefix           = "$sha256$"
+)
+
+var (
+	ErrUnknownHashFormat    = errors.New("unknown password hash format")
+	ErrPasswordMismatch     = errors.New("password does not match")
+	ErrUnknownPepperVersion = errors.New("unknown password pepper version")
+)
 
-func NewPasswordService() *PasswordService {
-	return &PasswordService{}
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// PasswordService hashes new passwords with the configured algorithm and
+// verifies hashes of any supported algorithm. Hashes are self-describing
+// (bcrypt's "$2a$<cost>$..." and the PHC string
+// "$argon2id$v=19$m=<KiB>,t=<iterations>,p=<threads>$<salt>$<key>"), so
+// NeedsRehash can tell when a stored hash falls behind the current settings.
+//
+// With a pepper configured, the password is first replaced by its
+// HMAC-SHA256 under a server-side key that is never stored in the database,
+// and the hash is prefixed with the key's version: "$pepper$v=<n>$argon2id$...".
+// Retired versions stay in the pepper file so old hashes still verify until
+// they are upgraded on the user's next successful login.
+//
+// Without a pepper, bcrypt is given the base64 SHA-256 of passwords longer
+// than its 72 byte limit, marked by a "$sha256$" prefix on the hash.
+type PasswordService struct {
+	algorithm     string
+	bcryptCost    int
+	argon2        argon2Params
+	peppers       map[int][]byte
+	pepperVersion int
+}
+
+func NewPasswordService(cfg config.Config) (*PasswordService, error) {
+	p := &PasswordService{
+		algorithm:  cfg.PasswordHashAlgorithm,
+		bcryptCost: cfg.PasswordBcryptCost,
+		argon2: argon2Params{
+			memory:      cfg.PasswordArgon2Memory,
+			iterations:  cfg.PasswordArgon2Iterations,
+			parallelism: cfg.PasswordArgon2Parallelism,
+		},
+	}
+
+	if cfg.PasswordPepperFile != "" {
+		peppers, current, err := loadPepperKeys(cfg.PasswordPepperFile)
+		if err != nil {
+			return nil, err
+		}
+		p.peppers, p.pepperVersion = peppers, current
+	}
+	return p, nil
+}
+
+// loadPepperKeys reads "<version>:<key>" lines; the highest version is used
+// for new hashes.
+func loadPepperKeys(path string) (map[int][]byte, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open PASSWORD_PEPPER_FILE: %w", err)
+	}
+	defer f.Close()
+
+	peppers := make(map[int][]byte)
+	current := 0
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Errors name the line, never its content: a malformed line may
+		// well be a key.
+		versionStr, key, ok := strings.Cut(line, ":")
+		version, err := strconv.Atoi(versionStr)
+		if !ok || err != nil || version < 1 {
+			return nil, 0, fmt.Errorf("invalid PASSWORD_PEPPER_FILE line %d: want <version>:<key> with a positive version", lineNo)
+		}
+		if len(key) < minPepperKeyLength {
+			return nil, 0, fmt.Errorf("pepper version %d is shorter than %d bytes", version, minPepperKeyLength)
+		}
+		if _, dup := peppers[version]; dup {
+			return nil, 0, fmt.Errorf("duplicate pepper version %d", version)
+		}
+
+		peppers[version] = []byte(key)
+		current = max(current, version)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	if current == 0 {
+		return nil, 0, errors.New("PASSWORD_PEPPER_FILE contains no keys")
+	}
+	return peppers, current, nil
 }
 
 func (p *PasswordService) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	if p.pepperVersion == 0 {
+		return p.hashPlain(password)
+	}
+
+	hash, err := p.hashPlain(pepper(p.peppers[p.pepperVersion], password))
+	if err != nil {
+		return "", err
+	}
+	return pepperPrefix + strconv.Itoa(p.pepperVersion) + hash, nil
+}
+
+func (p *PasswordService) hashPlain(password string) (string, error) {
+	switch p.algorithm {
+	case AlgorithmBcrypt:
+		if len(password) > bcryptMaxPasswordLength {
+			bytes, err := bcrypt.GenerateFromPassword([]byte(prehash(password)), p.bcryptCost)
+			return prehashPrefix + string(bytes), err
+		}
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), p.bcryptCost)
+		return string(bytes), err
+	case AlgorithmArgon2id:
+		return p.hashArgon2id(password)
+	default:
+		return "", fmt.Errorf("unsupported password hash algorithm %q", p.algorithm)
+	}
 }
 
 func (p *PasswordService) CheckPassword(hash, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	version, inner, peppered, err := splitPepper(hash)
+	if err != nil {
+		return err
+	}
+	if !peppered {
+		return checkPlain(hash, password)
+	}
+
+	key, ok := p.peppers[version]
+	if !ok {
+		return fmt.Errorf("%w %d", ErrUnknownPepperVersion, version)
+	}
+	return checkPlain(inner, pepper(key, password))
+}
+
+func checkPlain(hash, password string) error {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return checkArgon2id(hash, password)
+	case isBcryptHash(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	case strings.HasPrefix(hash, prehashPrefix) && isBcryptHash(hash[len(prehashPrefix):]):
+		return bcrypt.CompareHashAndPassword([]byte(hash[len(prehashPrefix):]), []byte(prehash(password)))
+	default:
+		return ErrUnknownHashFormat
+	}
+}
+
+// NeedsRehash reports whether hash was produced by a different algorithm,
+// with different parameters or under a different pepper version than new
+// hashes would be.
+func (p *PasswordService) NeedsRehash(hash string) bool {
+	version, inner, peppered, err := splitPepper(hash)
+	if err != nil || version != p.pepperVersion {
+		return true
+	}
+	if peppered {
+		hash = inner
+	}
+	hash = strings.TrimPrefix(hash, prehashPrefix)
+
+	switch p.algorithm {
+	case AlgorithmBcrypt:
+		if !isBcryptHash(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != p.bcryptCost
+	case AlgorithmArgon2id:
+		params, _, _, err := decodeArgon2id(hash)
+		return err != nil || params != p.argon2
+	default:
+		return false
+	}
+}
+
+// pepper returns the base64 HMAC of the password, which also keeps bcrypt's
+// 72 byte input limit from truncating long passwords.
+func pepper(key []byte, password string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// prehash returns the base64 SHA-256 of the password, which fits bcrypt's
+// input limit and contains no NUL bytes.
+func prehash(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// splitPepper separates "$pepper$v=<n>" from the inner hash. Unpeppered
+// hashes are returned with version 0.
+func splitPepper(hash string) (int, string, bool, error) {
+	rest, ok := strings.CutPrefix(hash, pepperPrefix)
+	if !ok {
+		return 0, hash, false, nil
+	}
+
+	i := strings.IndexByte(rest, '$')
+	if i < 0 {
+		return 0, "", false, ErrUnknownHashFormat
+	}
+
+	version, err := strconv.Atoi(rest[:i])
+	if err != nil || version < 1 {
+		return 0, "", false, ErrUnknownHashFormat
+	}
+	return version, rest[i:], true, nil
+}
+
+func (p *PasswordService) hashArgon2id(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.argon2.iterations, p.argon2.memory, p.argon2.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.argon2.memory, p.argon2.iterations, p.argon2.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func checkArgon2id(hash, password string) error {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 key: %w", err)
+	}
+
+	return params, salt, key, nil
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+const generatedPasswordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!#%+-=?@_"
+
+// GeneratePassword returns a random password of the given length for
+// operator-issued credentials. It is redrawn until it contains every
+// character class a password policy can require.
+func GeneratePassword(length int) (string, error) {
+	if length < 4 {
+		return "", errors.New("generated passwords need at least 4 characters")
+	}
+
+	// Bytes at or above limit are skipped so every character is equally likely.
+	limit := 256 - 256%len(generatedPasswordAlphabet)
+	random := make([]byte, 64)
+	for {
+		password := make([]byte, 0, length)
+		for len(password) < length {
+			if _, err := rand.Read(random); err != nil {
+				return "", err
+			}
+			for _, b := range random {
+				if int(b) < limit && len(password) < length {
+					password = append(password, generatedPasswordAlphabet[int(b)%len(generatedPasswordAlphabet)])
+				}
+			}
+		}
+
+		var lower, upper, digit, symbol bool
+		for _, c := range password {
+			switch {
+			case c >= 'a' && c <= 'z':
+				lower = true
+			case c >= 'A' && c <= 'Z':
+				upper = true
+			case c >= '0' && c <= '9':
+				digit = true
+			default:
+				symbol = true
+			}
+		}
+		if lower && upper && digit && symbol {
+			return string(password), nil
+		}
+	}
 }
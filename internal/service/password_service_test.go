@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testPasswordConfig(algorithm string) config.Config {
+	return config.Config{
+		PasswordHashAlgorithm:     algorithm,
+		PasswordBcryptCost:        bcrypt.MinCost,
+		PasswordArgon2Memory:      1024,
+		PasswordArgon2Iterations:  1,
+		PasswordArgon2Parallelism: 1,
+	}
+}
+
+func newTestPasswordService(t *testing.T, cfg config.Config) *service.PasswordService {
+	t.Helper()
+	hasher, err := service.NewPasswordService(cfg)
+	require.NoError(t, err)
+	return hasher
+}
+
+func TestPasswordService(t *testing.T) {
+	t.Run("Argon2idRoundTrip", func(t *testing.T) {
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+
+		hash, err := hasher.HashPassword("correct horse battery staple")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.NoError(t, hasher.CheckPassword(hash, "correct horse battery staple"))
+		assert.Error(t, hasher.CheckPassword(hash, "wrong"))
+		assert.False(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("LongPasswordsAreNotTruncated", func(t *testing.T) {
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+		long := strings.Repeat("a", 100)
+
+		hash, err := hasher.HashPassword(long + "1")
+
+		require.NoError(t, err)
+		assert.Error(t, hasher.CheckPassword(hash, long+"2"))
+	})
+
+	t.Run("BcryptLongPassword", func(t *testing.T) {
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmBcrypt))
+		long := strings.Repeat("a", 100)
+
+		hash, err := hasher.HashPassword(long + "1")
+
+		require.NoError(t, err, "bcrypt alone rejects more than 72 bytes")
+		assert.True(t, strings.HasPrefix(hash, "$sha256$$2a$04$"))
+		assert.NoError(t, hasher.CheckPassword(hash, long+"1"))
+		assert.Error(t, hasher.CheckPassword(hash, long+"2"), "not truncated either")
+		assert.False(t, hasher.NeedsRehash(hash))
+
+		short, err := hasher.HashPassword("password")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(short, "$2a$04$"), "passwords bcrypt accepts are hashed as before")
+
+		argon := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+		assert.NoError(t, argon.CheckPassword(hash, long+"1"))
+		assert.True(t, argon.NeedsRehash(hash))
+	})
+
+	t.Run("BcryptHashNeedsRehashUnderArgon2id", func(t *testing.T) {
+		legacy := newTestPasswordService(t, testPasswordConfig(service.AlgorithmBcrypt))
+		hash, err := legacy.HashPassword("password")
+		require.NoError(t, err)
+
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+
+		assert.NoError(t, hasher.CheckPassword(hash, "password"))
+		assert.True(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("ChangedParametersNeedRehash", func(t *testing.T) {
+		hash, err := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id)).HashPassword("password")
+		require.NoError(t, err)
+
+		cfg := testPasswordConfig(service.AlgorithmArgon2id)
+		cfg.PasswordArgon2Iterations = 2
+		hasher := newTestPasswordService(t, cfg)
+
+		assert.NoError(t, hasher.CheckPassword(hash, "password"))
+		assert.True(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("BcryptCostChangeNeedsRehash", func(t *testing.T) {
+		hash, err := newTestPasswordService(t, testPasswordConfig(service.AlgorithmBcrypt)).HashPassword("password")
+		require.NoError(t, err)
+
+		cfg := testPasswordConfig(service.AlgorithmBcrypt)
+		cfg.PasswordBcryptCost = bcrypt.MinCost + 1
+
+		assert.True(t, newTestPasswordService(t, cfg).NeedsRehash(hash))
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+
+		assert.ErrorIs(t, hasher.CheckPassword("plaintext", "plaintext"), service.ErrUnknownHashFormat)
+	})
+}
+
+func TestPasswordPepper(t *testing.T) {
+	writePeppers := func(t *testing.T, lines string) string {
+		path := filepath.Join(t.TempDir(), "peppers")
+		require.NoError(t, os.WriteFile(path, []byte(lines), 0o600))
+		return path
+	}
+
+	cfg := testPasswordConfig(service.AlgorithmArgon2id)
+	cfg.PasswordPepperFile = writePeppers(t, "1:first-pepper-key-0123456789\n")
+	v1 := newTestPasswordService(t, cfg)
+
+	hash, err := v1.HashPassword("password")
+	require.NoError(t, err)
+
+	t.Run("VersionIsStoredInHash", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(hash, "$pepper$v=1$argon2id$"))
+		assert.NoError(t, v1.CheckPassword(hash, "password"))
+		assert.Error(t, v1.CheckPassword(hash, "wrong"))
+		assert.False(t, v1.NeedsRehash(hash))
+	})
+
+	t.Run("RotationKeepsOldHashesValid", func(t *testing.T) {
+		cfg := cfg
+		cfg.PasswordPepperFile = writePeppers(t, "# retired\n1:first-pepper-key-0123456789\n2:second-pepper-key-0123456789\n")
+		v2 := newTestPasswordService(t, cfg)
+
+		assert.NoError(t, v2.CheckPassword(hash, "password"))
+		assert.True(t, v2.NeedsRehash(hash))
+
+		upgraded, err := v2.HashPassword("password")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(upgraded, "$pepper$v=2$"))
+	})
+
+	t.Run("UnpepperedHashNeedsRehash", func(t *testing.T) {
+		plain, err := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id)).HashPassword("password")
+		require.NoError(t, err)
+
+		assert.NoError(t, v1.CheckPassword(plain, "password"))
+		assert.True(t, v1.NeedsRehash(plain))
+	})
+
+	t.Run("MissingPepperKey", func(t *testing.T) {
+		hasher := newTestPasswordService(t, testPasswordConfig(service.AlgorithmArgon2id))
+
+		assert.ErrorIs(t, hasher.CheckPassword(hash, "password"), service.ErrUnknownPepperVersion)
+	})
+
+	t.Run("ShortKeyIsRejected", func(t *testing.T) {
+		cfg := cfg
+		cfg.PasswordPepperFile = writePeppers(t, "1:short\n")
+
+		_, err := service.NewPasswordService(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("MalformedLineIsNotEchoed", func(t *testing.T) {
+		cfg := cfg
+		cfg.PasswordPepperFile = writePeppers(t, "1:first-pepper-key-0123456789\nsecret-pepper-without-version\n")
+
+		_, err := service.NewPasswordService(cfg)
+
+		assert.ErrorContains(t, err, "line 2")
+		assert.NotContains(t, err.Error(), "secret-pepper")
+	})
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "auth"
+
+// activeSessionsTimeout bounds each refresh of the session count.
+const activeSessionsTimeout = 2 * time.Second
+
+// PrometheusMetrics implements domain.Metrics on its own registry, which
+// also carries the Go runtime and process collectors.
+type PrometheusMetrics struct {
+	registry *prometheus.Registry
+
+	authOutcomes     *prometheus.CounterVec
+	passwordHash     *prometheus.HistogramVec
+	tokenValidation  *prometheus.HistogramVec
+	revocationChecks *prometheus.CounterVec
+	dependencyErrors *prometheus.CounterVec
+}
+
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		authOutcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "attempts_total",
+			Help:      "Register, login, refresh and logout attempts by outcome and failure reason.",
+		}, []string{"flow", "outcome", "reason"}),
+		passwordHash: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "password_hash_duration_seconds",
+			Help:      "Time spent hashing and verifying passwords.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 10),
+		}, []string{"operation"}),
+		tokenValidation: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "token_validation_duration_seconds",
+			Help:      "Time spent validating JWTs, including key lookups.",
+			Buckets:   prometheus.ExponentialBuckets(0.00005, 4, 8),
+		}, []string{"type", "result"}),
+		revocationChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "revocation_checks_total",
+			Help:      "Token blacklist lookups by caller and result (revoked, valid or error).",
+		}, []string{"source", "result"}),
+		dependencyErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dependency_errors_total",
+			Help:      "Failed database and Redis operations.",
+		}, []string{"dependency", "operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.authOutcomes,
+		m.passwordHash,
+		m.tokenValidation,
+		m.revocationChecks,
+		m.dependencyErrors,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *PrometheusMetrics) AuthOutcome(flow, outcome, reason string) {
+	m.authOutcomes.WithLabelValues(flow, outcome, reason).Inc()
+}
+
+func (m *PrometheusMetrics) ObservePasswordHash(operation string, d time.Duration) {
+	m.passwordHash.WithLabelValues(operation).Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveTokenValidation(refresh bool, d time.Duration, err error) {
+	tokenType, result := "access", "valid"
+	if refresh {
+		tokenType = "refresh"
+	}
+	if err != nil {
+		result = "invalid"
+	}
+	m.tokenValidation.WithLabelValues(tokenType, result).Observe(d.Seconds())
+}
+
+func (m *PrometheusMetrics) RevocationCheck(source string, revoked bool, err error) {
+	result := "valid"
+	switch {
+	case err != nil:
+		result = "error"
+	case revoked:
+		result = "revoked"
+	}
+	m.revocationChecks.WithLabelValues(source, result).Inc()
+}
+
+func (m *PrometheusMetrics) DependencyError(dependency, operation string) {
+	m.dependencyErrors.WithLabelValues(dependency, operation).Inc()
+}
+
+// TrackActiveSessions exports count as the auth_active_sessions gauge,
+// refreshed now and then every interval until ctx is done, so scrapes never
+// reach the database. A failed count is reported as NaN.
+func (m *PrometheusMetrics) TrackActiveSessions(ctx context.Context, count func(ctx context.Context) (int64, error), interval time.Duration) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "active_sessions",
+		Help:      "Sessions that have not expired or been revoked, as of the last refresh.",
+	})
+	m.registry.MustRegister(gauge)
+
+	refresh := func() {
+		countCtx, cancel := context.WithTimeout(ctx, activeSessionsTimeout)
+		defer cancel()
+
+		n, err := count(countCtx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "failed to count active sessions", "error", err)
+			}
+			gauge.Set(math.NaN())
+			return
+		}
+		gauge.Set(float64(n))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
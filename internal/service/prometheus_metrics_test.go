@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	// scrape returns the exposition served by m.
+	scrape := func(t *testing.T, m *service.PrometheusMetrics) string {
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		require.Equal(t, 200, rec.Code)
+		body, err := io.ReadAll(rec.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("Counters", func(t *testing.T) {
+		m := service.NewPrometheusMetrics()
+
+		m.AuthOutcome("login", "failure", "wrong password")
+		m.AuthOutcome("login", "failure", "wrong password")
+		m.RevocationCheck("middleware", true, nil)
+		m.RevocationCheck("middleware", false, errors.New("redis down"))
+		m.DependencyError("redis", "get")
+		m.ObservePasswordHash("verify", 30*time.Millisecond)
+		m.ObserveTokenValidation(true, time.Millisecond, errors.New("expired"))
+
+		body := scrape(t, m)
+		assert.Contains(t, body, `auth_attempts_total{flow="login",outcome="failure",reason="wrong password"} 2`)
+		assert.Contains(t, body, `auth_revocation_checks_total{result="revoked",source="middleware"} 1`)
+		assert.Contains(t, body, `auth_revocation_checks_total{result="error",source="middleware"} 1`)
+		assert.Contains(t, body, `auth_dependency_errors_total{dependency="redis",operation="get"} 1`)
+		assert.Contains(t, body, `auth_password_hash_duration_seconds_count{operation="verify"} 1`)
+		assert.Contains(t, body, `auth_token_validation_duration_seconds_count{result="invalid",type="refresh"} 1`)
+		assert.Contains(t, body, "go_goroutines")
+		assert.NotContains(t, body, "auth_active_sessions", "sessions are not tracked")
+	})
+
+	t.Run("ActiveSessions", func(t *testing.T) {
+		m := service.NewPrometheusMetrics()
+		var calls atomic.Int64
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			m.TrackActiveSessions(ctx, func(context.Context) (int64, error) {
+				return 40 + calls.Add(1), nil
+			}, time.Hour)
+		}()
+		defer func() { cancel(); <-done }()
+
+		require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+		for i := 0; i < 3; i++ {
+			assert.Contains(t, scrape(t, m), "auth_active_sessions 41")
+		}
+		assert.Equal(t, int64(1), calls.Load(), "scrapes read the cached count")
+	})
+
+	t.Run("ActiveSessionsRefresh", func(t *testing.T) {
+		m := service.NewPrometheusMetrics()
+		var calls atomic.Int64
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			m.TrackActiveSessions(ctx, func(context.Context) (int64, error) {
+				if calls.Add(1) > 1 {
+					return 0, errors.New("database unavailable")
+				}
+				return 7, nil
+			}, 10*time.Millisecond)
+		}()
+		defer func() { cancel(); <-done }()
+
+		require.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+		assert.Contains(t, scrape(t, m), "auth_active_sessions NaN", "a failed count is not reported as the last value")
+	})
+}
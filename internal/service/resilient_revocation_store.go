@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+const (
+	// maxPendingReplays bounds the revocations kept in memory for replay to
+	// the primary store after an outage.
+	maxPendingReplays = 10000
+	// revocationReplayInterval is how often Run retries a replay that failed.
+	revocationReplayInterval = 5 * time.Second
+)
+
+type pendingRevocation struct {
+	token     string
+	expiresAt time.Time
+}
+
+// ResilientRevocationStore wraps the primary revocation store with an explicit
+// policy for when it fails, instead of silently treating every token as valid.
+//
+// In fallback mode every revocation is also written to the fallback store, so
+// it is complete even for revocations made while the primary was down. Those
+// are additionally queued and replayed to the primary by Run once it
+// recovers. Until the replay has finished, lookups the primary answers with
+// "not revoked" are checked against the fallback as well.
+type ResilientRevocationStore struct {
+	primary  domain.RevocationStore
+	fallback domain.RevocationStore
+	mode     domain.RevocationFailureMode
+
+	primaryErrors atomic.Uint64
+	fallbackOps   atomic.Uint64
+	failedOpen    atomic.Uint64
+	failedClosed  atomic.Uint64
+
+	mu            sync.Mutex
+	degradedSince *time.Time
+	lastError     string
+	pending       []pendingRevocation
+	// fallbackUntil is the latest expiry of revocations that did not fit in
+	// pending; the primary misses them until then.
+	fallbackUntil time.Time
+	wake          chan struct{}
+}
+
+func NewResilientRevocationStore(primary, fallback domain.RevocationStore, mode domain.RevocationFailureMode) (*ResilientRevocationStore, error) {
+	switch mode {
+	case domain.RevocationFailClosed, domain.RevocationFailOpen:
+	case domain.RevocationFailover:
+		if fallback == nil {
+			return nil, fmt.Errorf("revocation failure mode %q needs a fallback store", mode)
+		}
+	default:
+		return nil, fmt.Errorf("unknown revocation failure mode %q", mode)
+	}
+
+	return &ResilientRevocationStore{
+		primary:  primary,
+		fallback: fallback,
+		mode:     mode,
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+func (s *ResilientRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	err := s.primary.Revoke(ctx, token, expiresAt)
+	if err == nil {
+		s.recovered(ctx)
+	} else {
+		s.failed(err)
+	}
+
+	switch {
+	case s.mode == domain.RevocationFailover:
+		s.fallbackOps.Add(1)
+		if fbErr := s.fallback.Revoke(ctx, token, expiresAt); fbErr != nil {
+			if err != nil {
+				return fmt.Errorf("%w: primary: %v, fallback: %v", domain.ErrRevocationUnavailable, err, fbErr)
+			}
+			slog.WarnContext(ctx, "failed to write revocation to fallback store", "error", fbErr)
+		}
+		if err != nil {
+			s.queueReplay(token, expiresAt)
+		}
+		return nil
+	case err == nil:
+		return nil
+	case s.mode == domain.RevocationFailOpen:
+		s.failedOpen.Add(1)
+		slog.WarnContext(ctx, "revocation dropped, store unavailable (fail-open)", "error", err)
+		return nil
+	default:
+		s.failedClosed.Add(1)
+		return fmt.Errorf("%w: %v", domain.ErrRevocationUnavailable, err)
+	}
+}
+
+func (s *ResilientRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	revoked, err := s.primary.IsRevoked(ctx, token)
+	if err == nil {
+		s.recovered(ctx)
+		if !revoked && s.primaryIncomplete() {
+			return s.checkFallback(ctx, token), nil
+		}
+		return revoked, nil
+	}
+	s.failed(err)
+
+	switch s.mode {
+	case domain.RevocationFailover:
+		s.fallbackOps.Add(1)
+		revoked, fbErr := s.fallback.IsRevoked(ctx, token)
+		if fbErr != nil {
+			return false, fmt.Errorf("%w: primary: %v, fallback: %v", domain.ErrRevocationUnavailable, err, fbErr)
+		}
+		return revoked, nil
+	case domain.RevocationFailOpen:
+		s.failedOpen.Add(1)
+		return false, nil
+	default:
+		s.failedClosed.Add(1)
+		return false, fmt.Errorf("%w: %v", domain.ErrRevocationUnavailable, err)
+	}
+}
+
+func (s *ResilientRevocationStore) Status() domain.RevocationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return domain.RevocationStatus{
+		Mode:           s.mode,
+		Degraded:       s.degradedSince != nil,
+		DegradedSince:  s.degradedSince,
+		LastError:      s.lastError,
+		PrimaryErrors:  s.primaryErrors.Load(),
+		FallbackOps:    s.fallbackOps.Load(),
+		FailedOpen:     s.failedOpen.Load(),
+		FailedClosed:   s.failedClosed.Load(),
+		PendingReplays: len(s.pending),
+	}
+}
+
+// Run replays revocations queued during an outage to the primary store,
+// whenever it recovers, until ctx is cancelled.
+func (s *ResilientRevocationStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(revocationReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+		s.replay(ctx)
+	}
+}
+
+// replay writes the queued revocations to the primary, oldest first. Each
+// stays queued until the primary accepted it, so a failure only pauses the
+// replay until the primary recovers again.
+func (s *ResilientRevocationStore) replay(ctx context.Context) {
+	replayed := 0
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		if s.degradedSince != nil || len(s.pending) == 0 {
+			s.mu.Unlock()
+			break
+		}
+		p := s.pending[0]
+		s.mu.Unlock()
+
+		if err := s.primary.Revoke(ctx, p.token, p.expiresAt); err != nil {
+			s.failed(err)
+			slog.WarnContext(ctx, "failed to replay revocation, will retry", "error", err)
+			break
+		}
+
+		s.mu.Lock()
+		s.pending = s.pending[1:]
+		s.mu.Unlock()
+		replayed++
+	}
+
+	if replayed > 0 {
+		slog.InfoContext(ctx, "replayed revocations to the primary store", "replayed", replayed, "pending", s.Status().PendingReplays)
+	}
+}
+
+// primaryIncomplete reports whether the primary may still miss revocations
+// that only reached the fallback store.
+func (s *ResilientRevocationStore) primaryIncomplete() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending) > 0 || time.Now().Before(s.fallbackUntil)
+}
+
+// checkFallback is consulted in addition to a primary that answered. Its
+// failure is only logged, the primary's answer stands.
+func (s *ResilientRevocationStore) checkFallback(ctx context.Context, token string) bool {
+	s.fallbackOps.Add(1)
+	revoked, err := s.fallback.IsRevoked(ctx, token)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check fallback store during replay", "error", err)
+		return false
+	}
+	return revoked
+}
+
+func (s *ResilientRevocationStore) failed(err error) {
+	s.primaryErrors.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+	if s.degradedSince == nil {
+		now := time.Now().UTC()
+		s.degradedSince = &now
+		slog.Warn("revocation store degraded", "mode", s.mode, "error", err)
+	}
+}
+
+// recovered clears the degraded state and wakes Run to replay revocations
+// that only reached the fallback store during the outage.
+func (s *ResilientRevocationStore) recovered(ctx context.Context) {
+	s.mu.Lock()
+	if s.degradedSince == nil {
+		s.mu.Unlock()
+		return
+	}
+	since := *s.degradedSince
+	s.degradedSince = nil
+	pending := len(s.pending)
+	s.mu.Unlock()
+
+	slog.InfoContext(ctx, "revocation store recovered", "degraded_for", time.Since(since).Round(time.Second).String(), "replaying", pending)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// queueReplay queues a revocation for the primary. When the queue is full the
+// revocation is only in the fallback store, which lookups then keep
+// consulting until it has expired.
+func (s *ResilientRevocationStore) queueReplay(token string, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= maxPendingReplays {
+		if expiresAt.After(s.fallbackUntil) {
+			s.fallbackUntil = expiresAt
+		}
+		slog.Warn("revocation replay queue full, primary store will miss revocations until they expire",
+			"max_pending", maxPendingReplays, "consult_fallback_until", s.fallbackUntil)
+		return
+	}
+	s.pending = append(s.pending, pendingRevocation{token: token, expiresAt: expiresAt})
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRevocationStore is a memory store that can be switched off.
+type flakyRevocationStore struct {
+	domain.RevocationStore
+	down bool
+}
+
+func (f *flakyRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	if f.down {
+		return errors.New("connection refused")
+	}
+	return f.RevocationStore.Revoke(ctx, token, expiresAt)
+}
+
+func (f *flakyRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	if f.down {
+		return false, errors.New("connection refused")
+	}
+	return f.RevocationStore.IsRevoked(ctx, token)
+}
+
+func TestResilientRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	t.Run("FailClosed", func(t *testing.T) {
+		primary := &flakyRevocationStore{RevocationStore: repository.NewMemoryRevocationStore(), down: true}
+		store, err := service.NewResilientRevocationStore(primary, nil, domain.RevocationFailClosed)
+		require.NoError(t, err)
+
+		_, err = store.IsRevoked(ctx, "token")
+		assert.ErrorIs(t, err, domain.ErrRevocationUnavailable)
+		assert.ErrorIs(t, store.Revoke(ctx, "token", expiry), domain.ErrRevocationUnavailable)
+
+		status := store.Status()
+		assert.True(t, status.Degraded)
+		assert.Equal(t, uint64(2), status.FailedClosed)
+	})
+
+	t.Run("FailOpen", func(t *testing.T) {
+		primary := &flakyRevocationStore{RevocationStore: repository.NewMemoryRevocationStore(), down: true}
+		store, err := service.NewResilientRevocationStore(primary, nil, domain.RevocationFailOpen)
+		require.NoError(t, err)
+
+		revoked, err := store.IsRevoked(ctx, "token")
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		assert.Equal(t, uint64(1), store.Status().FailedOpen)
+
+		primary.down = false
+		_, err = store.IsRevoked(ctx, "token")
+		assert.NoError(t, err)
+		assert.False(t, store.Status().Degraded)
+	})
+
+	t.Run("FallbackAndReplay", func(t *testing.T) {
+		primary := &flakyRevocationStore{RevocationStore: repository.NewMemoryRevocationStore(), down: true}
+		store, err := service.NewResilientRevocationStore(primary, repository.NewMemoryRevocationStore(), domain.RevocationFailover)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Revoke(ctx, "token", expiry))
+
+		revoked, err := store.IsRevoked(ctx, "token")
+		require.NoError(t, err)
+		assert.True(t, revoked, "revocation made during the outage must be visible through the fallback")
+
+		// Until the replay ran, the primary alone would accept the token.
+		primary.down = false
+		revoked, err = store.IsRevoked(ctx, "token")
+		require.NoError(t, err)
+		assert.True(t, revoked, "the fallback is consulted until the replay finished")
+		assert.Equal(t, 1, store.Status().PendingReplays)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go store.Run(runCtx)
+		_, err = store.IsRevoked(ctx, "other")
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return store.Status().PendingReplays == 0 }, 5*time.Second, 10*time.Millisecond)
+		revoked, err = primary.IsRevoked(ctx, "token")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("FailedReplayIsRequeued", func(t *testing.T) {
+		primary := &flakyRevocationStore{RevocationStore: repository.NewMemoryRevocationStore(), down: true}
+		store, err := service.NewResilientRevocationStore(primary, repository.NewMemoryRevocationStore(), domain.RevocationFailover)
+		require.NoError(t, err)
+		require.NoError(t, store.Revoke(ctx, "token", expiry))
+
+		// The primary answers one lookup, then fails again before the replay.
+		primary.down = false
+		_, err = store.IsRevoked(ctx, "other")
+		require.NoError(t, err)
+		primary.down = true
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go store.Run(runCtx)
+
+		require.Eventually(t, func() bool { return store.Status().Degraded }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, 1, store.Status().PendingReplays, "the revocation stays queued")
+	})
+
+	t.Run("FallbackRequiresStore", func(t *testing.T) {
+		_, err := service.NewResilientRevocationStore(repository.NewMemoryRevocationStore(), nil, domain.RevocationFailover)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	constant "go-auth-service/internal/constants"
+	"go-auth-service/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revocationCacheScanCount     = 1000
+	revocationCacheSweepInterval = time.Minute
+	revocationCacheRetryDelay    = time.Second
+)
+
+// RevocationCache answers IsRevoked from an in-process copy of every revoked
+// token in Redis, so authenticated requests normally need no round trip.
+//
+// The copy is loaded with SCAN over the blacklist keys and kept current by a
+// pub/sub channel on which every instance announces its revocations, as the
+// SHA-256 of the token so that the channel never carries usable tokens. Local
+// answers are only given while the copy is known to be complete: between
+// subscribing and finishing the SCAN, after a lost subscription, or when the
+// copy would exceed maxEntries, lookups go to the underlying store instead.
+//
+// An announcement that fails to publish is retried by Run until it goes out;
+// until then other instances may still accept the token.
+type RevocationCache struct {
+	store      domain.RevocationStore
+	client     redis.UniversalClient
+	keyPrefix  string
+	channel    string
+	maxEntries int
+
+	mu      sync.RWMutex
+	synced  bool
+	revoked map[[sha256.Size]byte]time.Time
+
+	// unannounced holds messages whose publish failed, oldest first.
+	announceMu  sync.Mutex
+	unannounced []string
+}
+
+// NewRevocationCache caches the revocations of a Redis store created with the
+// same keyPrefix.
+func NewRevocationCache(store domain.RevocationStore, client redis.UniversalClient, keyPrefix, channel string, maxEntries int) *RevocationCache {
+	return &RevocationCache{
+		store:      store,
+		client:     client,
+		keyPrefix:  keyPrefix + constant.STR_BLACKLIST,
+		channel:    channel,
+		maxEntries: maxEntries,
+		revoked:    make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// Revoke writes through to the store and announces the revocation to every
+// instance, this one included.
+func (c *RevocationCache) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	if err := c.store.Revoke(ctx, token, expiresAt); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	c.add(hash, expiresAt)
+	msg := strconv.FormatInt(expiresAt.UnixMilli(), 10) + " " + hex.EncodeToString(hash[:])
+	if err := c.client.Publish(ctx, c.channel, msg).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to announce token revocation, will retry", "error", err)
+		c.announceMu.Lock()
+		c.unannounced = append(c.unannounced, msg)
+		c.announceMu.Unlock()
+	}
+	return nil
+}
+
+// Unannounced returns the number of revocations other instances have not been
+// told about yet.
+func (c *RevocationCache) Unannounced() int {
+	c.announceMu.Lock()
+	defer c.announceMu.Unlock()
+	return len(c.unannounced)
+}
+
+// announce retries the announcements whose publish failed, in order, and
+// stops at the first that fails again.
+func (c *RevocationCache) announce(ctx context.Context) {
+	c.announceMu.Lock()
+	defer c.announceMu.Unlock()
+
+	for len(c.unannounced) > 0 {
+		if err := c.client.Publish(ctx, c.channel, c.unannounced[0]).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to announce token revocations", "pending", len(c.unannounced), "error", err)
+			return
+		}
+		c.unannounced = c.unannounced[1:]
+	}
+}
+
+func (c *RevocationCache) IsRevoked(ctx context.Context, token string) (bool, error) {
+	c.mu.RLock()
+	if c.synced {
+		exp, ok := c.revoked[sha256.Sum256([]byte(token))]
+		c.mu.RUnlock()
+		return ok && exp.After(time.Now()), nil
+	}
+	c.mu.RUnlock()
+
+	return c.store.IsRevoked(ctx, token)
+}
+
+// Run keeps the cache in sync until ctx is cancelled.
+func (c *RevocationCache) Run(ctx context.Context) {
+	pubsub := c.client.Subscribe(ctx, c.channel)
+	defer pubsub.Close()
+
+	subscribed := false
+	lastSweep := time.Now()
+	for {
+		wait := revocationCacheSweepInterval
+		if c.Unannounced() > 0 {
+			c.announce(ctx)
+			wait = revocationCacheRetryDelay
+		}
+		msg, err := pubsub.ReceiveTimeout(ctx, wait)
+
+		if time.Since(lastSweep) >= revocationCacheSweepInterval {
+			c.sweep()
+			lastSweep = time.Now()
+		}
+
+		var netErr net.Error
+		switch {
+		case err == nil:
+		case errors.As(err, &netErr) && netErr.Timeout():
+			// Idle channel. Retry a reload that failed or overflowed earlier.
+			if subscribed && !c.isSynced() {
+				c.resync(ctx)
+			}
+			continue
+		default:
+			subscribed = false
+			c.setSynced(false)
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "revocation cache subscription lost", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(revocationCacheRetryDelay):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			// (Re)subscribed: anything published while disconnected is only
+			// visible through a full reload.
+			subscribed = true
+			c.resync(ctx)
+		case *redis.Message:
+			c.handleMessage(msg.Payload)
+		}
+	}
+}
+
+func (c *RevocationCache) resync(ctx context.Context) {
+	if err := c.reload(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to load revoked tokens", "error", err)
+		c.setSynced(false)
+		return
+	}
+	c.setSynced(true)
+}
+
+func (c *RevocationCache) handleMessage(payload string) {
+	expiryStr, digest, ok := strings.Cut(payload, " ")
+	if !ok {
+		return
+	}
+	expiryMs, err := strconv.ParseInt(expiryStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	var hash [sha256.Size]byte
+	if len(digest) != hex.EncodedLen(sha256.Size) {
+		// Instances running an older version announce the token itself.
+		hash = sha256.Sum256([]byte(digest))
+	} else if _, err := hex.Decode(hash[:], []byte(digest)); err != nil {
+		return
+	}
+	c.add(hash, time.UnixMilli(expiryMs))
+}
+
+func (c *RevocationCache) add(hash [sha256.Size]byte, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revoked[hash] = expiresAt
+	if c.synced && len(c.revoked) > c.maxEntries {
+		slog.Warn("revocation cache full, falling back to the store", "max_entries", c.maxEntries)
+		c.synced = false
+	}
+}
+
+// reload replaces the cache with the revocations currently in Redis. In
+// cluster mode every master is scanned, as SCAN only covers one node.
+func (c *RevocationCache) reload(ctx context.Context) error {
+	revoked := make(map[[sha256.Size]byte]time.Time)
+	var mu sync.Mutex
+
+	scan := func(ctx context.Context, client redis.UniversalClient) error {
+		iter := client.Scan(ctx, 0, c.keyPrefix+"*", revocationCacheScanCount).Iterator()
+		var keys []string
+		flush := func() error {
+			if len(keys) == 0 {
+				return nil
+			}
+
+			pipe := client.Pipeline()
+			ttls := make([]*redis.DurationCmd, len(keys))
+			for i, key := range keys {
+				ttls[i] = pipe.PTTL(ctx, key)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			mu.Lock()
+			defer mu.Unlock()
+			for i, key := range keys {
+				if ttl := ttls[i].Val(); ttl > 0 {
+					revoked[sha256.Sum256([]byte(strings.TrimPrefix(key, c.keyPrefix)))] = now.Add(ttl)
+				}
+			}
+			keys = keys[:0]
+			if len(revoked) > c.maxEntries {
+				return fmt.Errorf("more than %d revoked tokens, cache disabled until the next reload", c.maxEntries)
+			}
+			return nil
+		}
+
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+			if len(keys) == revocationCacheScanCount {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		return flush()
+	}
+
+	var err error
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	} else {
+		err = scan(ctx, c.client)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keep revocations announced while the scan was running.
+	for hash, exp := range c.revoked {
+		if _, ok := revoked[hash]; !ok && exp.After(time.Now()) {
+			revoked[hash] = exp
+		}
+	}
+	c.revoked = revoked
+	return nil
+}
+
+func (c *RevocationCache) sweep() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for hash, exp := range c.revoked {
+		if !exp.After(now) {
+			delete(c.revoked, hash)
+		}
+	}
+}
+
+func (c *RevocationCache) isSynced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.synced
+}
+
+func (c *RevocationCache) setSynced(synced bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.synced != synced {
+		slog.Info("revocation cache sync changed", "synced", synced, "entries", len(c.revoked))
+	}
+	c.synced = synced
+}
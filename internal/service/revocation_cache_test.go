@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failPublish fails PUBLISH commands while set.
+type failPublish struct {
+	set *atomic.Bool
+}
+
+func (h failPublish) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h failPublish) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if h.set.Load() && cmd.Name() == "publish" {
+			cmd.SetErr(errors.New("connection reset"))
+			return cmd.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h failPublish) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRevocationCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	expiry := time.Now().Add(time.Hour)
+
+	mr := miniredis.RunT(t)
+	newCache := func(client *redis.Client) *service.RevocationCache {
+		return service.NewRevocationCache(repository.NewRedisRevocationStore(client, ""), client, "", "revocations", 100)
+	}
+	// synced waits until cache answers from memory: the probe's key is gone
+	// from Redis, so only the cache can still know about it.
+	synced := func(t *testing.T, cache *service.RevocationCache, probe string) {
+		require.NoError(t, cache.Revoke(ctx, probe, expiry))
+		mr.FlushAll()
+		require.Eventually(t, func() bool {
+			revoked, err := cache.IsRevoked(ctx, probe)
+			return err == nil && revoked
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	subscriber := newCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	go subscriber.Run(ctx)
+	synced(t, subscriber, "probe")
+
+	t.Run("AnnouncesHashes", func(t *testing.T) {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		pubsub := client.Subscribe(ctx, "revocations")
+		defer pubsub.Close()
+		_, err := pubsub.Receive(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, newCache(client).Revoke(ctx, "secret.jwt.token", expiry))
+
+		msg, err := pubsub.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		hash := sha256.Sum256([]byte("secret.jwt.token"))
+		assert.NotContains(t, msg.Payload, "secret.jwt.token")
+		assert.Contains(t, msg.Payload, hex.EncodeToString(hash[:]))
+
+		// Announcements reach the other instances.
+		mr.FlushAll()
+		require.Eventually(t, func() bool {
+			revoked, err := subscriber.IsRevoked(ctx, "secret.jwt.token")
+			return err == nil && revoked
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("RetriesFailedAnnouncements", func(t *testing.T) {
+		failing := &atomic.Bool{}
+		failing.Store(true)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		client.AddHook(failPublish{set: failing})
+		publisher := newCache(client)
+
+		require.NoError(t, publisher.Revoke(ctx, "unannounced", expiry), "the store accepted the revocation")
+		assert.Equal(t, 1, publisher.Unannounced())
+
+		failing.Store(false)
+		go publisher.Run(ctx)
+
+		require.Eventually(t, func() bool { return publisher.Unannounced() == 0 }, 5*time.Second, 10*time.Millisecond)
+		mr.FlushAll()
+		require.Eventually(t, func() bool {
+			revoked, err := subscriber.IsRevoked(ctx, "unannounced")
+			return err == nil && revoked
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+// RevokingSessionRepository announces every session it ends to the
+// revocation store under domain.SessionRevocationKey, so that the access
+// tokens of the session are rejected from the revocation cache instead of by
+// looking the session up on every request. An announcement lives as long as
+// the last access token the session can have been issued.
+type RevokingSessionRepository struct {
+	domain.SessionRepository
+	tokens       domain.TokenManager
+	revocations  domain.RevocationStore
+	accessExpiry time.Duration
+}
+
+// NewRevokingSessionRepository wraps sessions. tokens reads the session of
+// the refresh tokens passed to DeleteByToken.
+func NewRevokingSessionRepository(sessions domain.SessionRepository, tokens domain.TokenManager, revocations domain.RevocationStore, accessExpiry time.Duration) *RevokingSessionRepository {
+	return &RevokingSessionRepository{
+		SessionRepository: sessions,
+		tokens:            tokens,
+		revocations:       revocations,
+		accessExpiry:      accessExpiry,
+	}
+}
+
+func (r *RevokingSessionRepository) Delete(ctx context.Context, id string) error {
+	if err := r.SessionRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.revoke(ctx, id)
+}
+
+// DeleteByToken takes the session from the claims of refreshToken. Tokens
+// issued before sessions were tracked have none, and neither have the access
+// tokens issued with them.
+func (r *RevokingSessionRepository) DeleteByToken(ctx context.Context, refreshToken string) error {
+	if err := r.SessionRepository.DeleteByToken(ctx, refreshToken); err != nil {
+		return err
+	}
+
+	claims, err := r.tokens.ValidateToken(refreshToken, true)
+	if err != nil || claims.SessionID == "" {
+		return nil
+	}
+	return r.revoke(ctx, claims.SessionID)
+}
+
+// DeleteByUser lists the sessions before ending them. A session started in
+// between is ended without being announced, so the access token of that one
+// login stays valid until it expires.
+func (r *RevokingSessionRepository) DeleteByUser(ctx context.Context, userID uint) (int64, error) {
+	sessions, err := r.SessionRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := r.SessionRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return deleted, err
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		errs = append(errs, r.revoke(ctx, session.ID))
+	}
+	return deleted, errors.Join(errs...)
+}
+
+func (r *RevokingSessionRepository) revoke(ctx context.Context, sessionID string) error {
+	if err := r.revocations.Revoke(ctx, domain.SessionRevocationKey(sessionID), time.Now().Add(r.accessExpiry)); err != nil {
+		return fmt.Errorf("session %s ended, but its access tokens could not be revoked: %w", sessionID, err)
+	}
+	return nil
+}
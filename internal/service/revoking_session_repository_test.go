@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokingSessionRepository(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Config{
+		DBDriver:         "sqlite",
+		DBPath:           ":memory:",
+		JWTSecret:        "0123456789abcdef0123456789abcdef",
+		JWTRefreshSecret: "fedcba9876543210fedcba9876543210",
+		JWTAccessExpiry:  time.Minute,
+		JWTRefreshExpiry: time.Hour,
+	}
+	db, err := infrastructure.NewDatabase(cfg)
+	require.NoError(t, err)
+	tokens := service.NewTokenService(cfg, nil)
+	revocations := repository.NewMemoryRevocationStore()
+	sessions := service.NewRevokingSessionRepository(repository.NewSessionRepository(db), tokens, revocations, cfg.JWTAccessExpiry)
+
+	start := func(t *testing.T, id string, userID uint) string {
+		token, err := tokens.GenerateRefreshToken(&domain.User{ID: userID}, id)
+		require.NoError(t, err)
+		require.NoError(t, sessions.Create(ctx, &domain.Session{ID: id, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, token))
+		return token
+	}
+	revoked := func(t *testing.T, id string) bool {
+		revoked, err := revocations.IsRevoked(ctx, domain.SessionRevocationKey(id))
+		require.NoError(t, err)
+		return revoked
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		start(t, "single", 1)
+		require.NoError(t, sessions.Delete(ctx, "single"))
+		assert.True(t, revoked(t, "single"))
+
+		assert.Error(t, sessions.Delete(ctx, "unknown"))
+		assert.False(t, revoked(t, "unknown"), "nothing ended, nothing announced")
+	})
+
+	t.Run("DeleteByToken", func(t *testing.T) {
+		token := start(t, "logout", 2)
+		require.NoError(t, sessions.DeleteByToken(ctx, token))
+		assert.True(t, revoked(t, "logout"))
+	})
+
+	t.Run("DeleteByUser", func(t *testing.T) {
+		start(t, "first", 3)
+		start(t, "second", 3)
+		start(t, "other", 4)
+
+		deleted, err := sessions.DeleteByUser(ctx, 3)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, deleted)
+		assert.True(t, revoked(t, "first"))
+		assert.True(t, revoked(t, "second"))
+		assert.False(t, revoked(t, "other"))
+	})
+}
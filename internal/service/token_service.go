@@ -1,7 +1,6 @@
 package service
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -9,81 +8,110 @@ import (
 	"go-auth-service/internal/domain"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type TokenService struct {
-	cfg config.Config
+	cfg  config.Config
+	keys *KeyRing
 }
 
-func NewTokenService(cfg config.Config) *TokenService {
-	return &TokenService{cfg: cfg}
-}
-
-func (t *TokenService) GenerateAccessToken(user *domain.User) (string, error) {
-	expiry, err := time.ParseDuration(t.cfg.JWTAccessExpiry)
-	if err != nil {
-		return "", err
+// NewTokenService signs and verifies tokens with the keys in keys, or with the
+// configured secrets only when keys is nil.
+func NewTokenService(cfg config.Config, keys *KeyRing) *TokenService {
+	if keys == nil {
+		keys = NewKeyRing(cfg, nil)
 	}
+	return &TokenService{cfg: cfg, keys: keys}
+}
 
+func (t *TokenService) GenerateAccessToken(user *domain.User, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
+		"jti":  uuid.NewString(),
 		"sub":  user.ID,
-		"exp":  time.Now().Add(expiry).Unix(),
+		"exp":  time.Now().Add(t.cfg.JWTAccessExpiry).Unix(),
 		"type": "access",
+		"role": user.Role,
+	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+	if user.MustChangePassword {
+		claims["pwd_change"] = true
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(t.cfg.JWTSecret))
+	return t.sign(domain.KeyPurposeAccess, claims)
 }
 
-func (t *TokenService) GenerateRefreshToken(user *domain.User) (string, error) {
-	expiry, err := time.ParseDuration(t.cfg.JWTRefreshExpiry)
-	if err != nil {
-		return "", err
-	}
-
+func (t *TokenService) GenerateRefreshToken(user *domain.User, sessionID string) (string, error) {
+	// The random ID keeps tokens issued within the same second distinct, so
+	// revoking or rotating one never affects another.
 	claims := jwt.MapClaims{
+		"jti":  uuid.NewString(),
 		"sub":  user.ID,
-		"exp":  time.Now().Add(expiry).Unix(),
+		"exp":  time.Now().Add(t.cfg.JWTRefreshExpiry).Unix(),
 		"type": "refresh",
 	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+
+	return t.sign(domain.KeyPurposeRefresh, claims)
+}
+
+func (t *TokenService) sign(purpose string, claims jwt.MapClaims) (string, error) {
+	kid, secret := t.keys.SigningKey(purpose)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(t.cfg.JWTRefreshSecret))
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(secret)
 }
 
 func (t *TokenService) ValidateToken(tokenString string, isRefresh bool) (*domain.TokenClaims, error) {
-	secret := t.cfg.JWTSecret
+	purpose := domain.KeyPurposeAccess
 	if isRefresh {
-		secret = t.cfg.JWTRefreshSecret
+		purpose = domain.KeyPurposeRefresh
 	}
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secret), nil
+		kid, _ := token.Header["kid"].(string)
+		return t.keys.VerificationKey(purpose, kid)
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		userIDFloat, ok := claims["sub"].(float64)
 		if !ok {
-			return nil, errors.New("invalid subject in token")
+			return nil, fmt.Errorf("%w: invalid subject", domain.ErrInvalidToken)
 		}
 
 		expFloat, ok := claims["exp"].(float64)
 		if !ok {
-			return nil, errors.New("invalid expiry in token")
+			return nil, fmt.Errorf("%w: invalid expiry", domain.ErrInvalidToken)
 		}
 
+		// Tokens issued before roles existed carry no role claim.
+		role, _ := claims["role"].(string)
+		// Likewise for tokens issued before sessions were tracked.
+		sessionID, _ := claims["sid"].(string)
+		mustChangePassword, _ := claims["pwd_change"].(bool)
+
 		return &domain.TokenClaims{
-			UserID: uint(userIDFloat),
-			Expiry: time.Unix(int64(expFloat), 0),
+			UserID:             uint(userIDFloat),
+			Role:               role,
+			SessionID:          sessionID,
+			MustChangePassword: mustChangePassword,
+			Expiry:             time.Unix(int64(expFloat), 0),
 		}, nil
 	}
 
-	return nil, errors.New("invalid token")
+	return nil, domain.ErrInvalidToken
 }
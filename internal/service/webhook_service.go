@@ -0,0 +1,290 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+)
+
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	webhookBatchSize  = 50
+	webhookMaxBackoff = 6 * time.Hour
+)
+
+// WebhookPayload is the JSON body POSTed to subscribers.
+type WebhookPayload struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	UserID     uint           `json:"user_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// SignWebhook returns the signature header value for a payload. The signed
+// string is "<timestamp>.<body>", so receivers can reject stale timestamps
+// to prevent replays without trusting an unsigned header.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookService turns domain events into persisted deliveries and sends them
+// with exponential backoff until they succeed or are dead-lettered.
+type WebhookService struct {
+	repo         domain.WebhookRepository
+	client       *http.Client
+	pollInterval time.Duration
+	backoffBase  time.Duration
+	maxAttempts  int
+}
+
+func NewWebhookService(cfg config.Config, repo domain.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		client:       newWebhookClient(cfg),
+		pollInterval: cfg.WebhookPollInterval,
+		backoffBase:  cfg.WebhookBackoffBase,
+		maxAttempts:  cfg.WebhookMaxAttempts,
+	}
+}
+
+func (s *WebhookService) Name() string {
+	return "webhook"
+}
+
+// Send enqueues one delivery per active subscription interested in the event.
+// Enqueueing the same event twice is a no-op, so outbox redelivery is safe.
+func (s *WebhookService) Send(ctx context.Context, event domain.DomainEvent) error {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(WebhookPayload(event))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var deliveries []domain.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Active || !sub.Wants(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(body),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+
+	return s.repo.EnqueueDeliveries(ctx, deliveries)
+}
+
+// Run delivers due webhooks until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	// Attempts start during the first timeout plus poll interval of the
+	// lease, and each may take one more timeout to finish.
+	start := time.Now()
+	lease := 2*s.client.Timeout + s.pollInterval
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to claim webhook deliveries", "error", err)
+		return
+	}
+
+	subs := make(map[uint]*domain.WebhookSubscription)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// An attempt started now could outlast the lease, and another worker
+		// would send the same delivery; leave the rest for the next claim.
+		if ctx.Err() != nil || time.Since(start)+s.client.Timeout >= lease {
+			break
+		}
+
+		sub, ok := subs[delivery.SubscriptionID]
+		if !ok {
+			sub, err = s.repo.GetSubscription(ctx, delivery.SubscriptionID)
+			if err != nil {
+				s.fail(ctx, delivery, fmt.Errorf("failed to load subscription: %w", err), errors.Is(err, domain.ErrWebhookNotFound))
+				continue
+			}
+			subs[delivery.SubscriptionID] = sub
+		}
+
+		s.attempt(ctx, sub, delivery)
+	}
+}
+
+func (s *WebhookService) attempt(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) {
+	statusCode, err := s.send(ctx, sub, delivery)
+
+	delivery.LastStatusCode = statusCode
+	if err != nil {
+		s.fail(ctx, delivery, err, false)
+		return
+	}
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.Status = domain.WebhookDeliveryDelivered
+	delivery.DeliveredAt = &now
+	delivery.LastError = ""
+	s.update(ctx, delivery)
+}
+
+// fail records a failed attempt: the delivery is retried with backoff, or
+// dead-lettered once it runs out of attempts or cannot succeed at all.
+func (s *WebhookService) fail(ctx context.Context, delivery *domain.WebhookDelivery, err error, permanent bool) {
+	delivery.Attempts++
+	delivery.LastError = truncate(err.Error(), 1024)
+	if permanent || delivery.Attempts >= s.maxAttempts {
+		delivery.Status = domain.WebhookDeliveryDead
+	} else {
+		delivery.NextAttemptAt = time.Now().UTC().Add(s.backoff(delivery.Attempts))
+	}
+	s.update(ctx, delivery)
+}
+
+func (s *WebhookService) update(ctx context.Context, delivery *domain.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		slog.WarnContext(ctx, "failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func (s *WebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newWebhookClient returns the client for subscriber URLs, which are
+// untrusted: it does not follow redirects or use a proxy, and unless
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS is set it refuses to connect to non-public
+// addresses. The address is checked after DNS resolution, so hostnames
+// resolving to internal addresses are refused too.
+func newWebhookClient(cfg config.Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !cfg.WebhookAllowPrivateNetworks {
+		dialer.Control = refusePrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.WebhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+var errWebhookAddressRefused = errors.New("webhook address is not public")
+
+// nonPublicPrefixes are ranges netip does not classify as private or
+// link-local but that still must not be reachable from webhooks.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64, which embeds IPv4 addresses
+}
+
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", errWebhookAddressRefused, ip)
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("%w: %s", errWebhookAddressRefused, ip)
+		}
+	}
+	return nil
+}
+
+// backoff doubles the delay after each failed attempt, capped at webhookMaxBackoff.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := float64(s.backoffBase) * math.Pow(2, float64(attempts-1))
+	if delay > float64(webhookMaxBackoff) {
+		return webhookMaxBackoff
+	}
+	return time.Duration(delay)
+}
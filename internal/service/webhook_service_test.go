@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-auth-service/config"
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/infrastructure"
+	"go-auth-service/internal/repository"
+	"go-auth-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWebhookService(t *testing.T) {
+	cfg := config.Config{
+		WebhookPollInterval: 10 * time.Millisecond,
+		WebhookTimeout:      2 * time.Second,
+		WebhookBackoffBase:  time.Hour,
+		WebhookMaxAttempts:  5,
+	}
+	// deliver sends one event to a new subscription for url and waits for
+	// the first attempt. before runs ahead of the worker.
+	deliver := func(t *testing.T, cfg config.Config, url string, before ...func(db *gorm.DB, sub *domain.WebhookSubscription)) *domain.WebhookDelivery {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		db, err := infrastructure.NewDatabase(config.Config{DBDriver: "sqlite", DBPath: ":memory:"})
+		require.NoError(t, err)
+		repo := repository.NewWebhookRepository(db, service.NewEnvelopeCipher(nil))
+		svc := service.NewWebhookService(cfg, repo)
+
+		sub := &domain.WebhookSubscription{URL: url, Secret: "whsec_test", EventTypes: []string{"*"}, Active: true}
+		require.NoError(t, repo.CreateSubscription(ctx, sub))
+		require.NoError(t, svc.Send(ctx, domain.NewDomainEvent(domain.EventUserRegistered, 1, nil)))
+		for _, fn := range before {
+			fn(db, sub)
+		}
+		go svc.Run(ctx)
+
+		var delivery *domain.WebhookDelivery
+		require.Eventually(t, func() bool {
+			deliveries, _, err := repo.ListDeliveries(ctx, "", 1, 10)
+			if err != nil || len(deliveries) != 1 || deliveries[0].Attempts == 0 {
+				return false
+			}
+			delivery = &deliveries[0]
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+		return delivery
+	}
+
+	t.Run("Delivers", func(t *testing.T) {
+		var signed atomic.Bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, _ := strconv.ParseInt(r.Header.Get(service.WebhookTimestampHeader), 10, 64)
+			signed.Store(r.Header.Get(service.WebhookSignatureHeader) == service.SignWebhook("whsec_test", timestamp, body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		allowed := cfg
+		allowed.WebhookAllowPrivateNetworks = true
+
+		delivery := deliver(t, allowed, server.URL)
+
+		assert.Equal(t, domain.WebhookDeliveryDelivered, delivery.Status)
+		assert.True(t, signed.Load())
+	})
+
+	t.Run("RefusesPrivateAddresses", func(t *testing.T) {
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+		defer server.Close()
+
+		for _, url := range []string{
+			server.URL,
+			"http://169.254.169.254/latest/meta-data/",
+			"http://10.0.0.1/",
+			"http://[::1]:8080/",
+			"http://[fd00::1]/",
+		} {
+			delivery := deliver(t, cfg, url)
+
+			assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status, url)
+			assert.Contains(t, delivery.LastError, "webhook address is not public", url)
+		}
+		assert.Zero(t, hits.Load())
+	})
+
+	t.Run("DoesNotFollowRedirects", func(t *testing.T) {
+		var followed atomic.Bool
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			followed.Store(true)
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+		defer server.Close()
+		allowed := cfg
+		allowed.WebhookAllowPrivateNetworks = true
+
+		delivery := deliver(t, allowed, server.URL)
+
+		assert.Equal(t, http.StatusFound, delivery.LastStatusCode)
+		assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status, "retried later")
+		assert.False(t, followed.Load())
+	})
+
+	t.Run("DeletedSubscription", func(t *testing.T) {
+		delivery := deliver(t, cfg, "https://example.com/hook", func(db *gorm.DB, sub *domain.WebhookSubscription) {
+			require.NoError(t, db.Delete(&domain.WebhookSubscription{}, sub.ID).Error)
+		})
+
+		assert.Equal(t, domain.WebhookDeliveryDead, delivery.Status, "not leased again and again")
+		assert.Contains(t, delivery.LastError, domain.ErrWebhookNotFound.Error())
+	})
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+
+	"go-auth-service/internal/domain"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+type auditUsecase struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewAuditUsecase(auditRepo domain.AuditRepository) domain.AuditUsecase {
+	return &auditUsecase{auditRepo: auditRepo}
+}
+
+func (u *auditUsecase) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultAuditPageSize
+	}
+	if filter.PageSize > maxAuditPageSize {
+		filter.PageSize = maxAuditPageSize
+	}
+
+	return u.auditRepo.List(ctx, filter)
+}
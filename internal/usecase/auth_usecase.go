@@ -3,126 +3,696 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"time"
 
-	constant "go-auth-service/internal/constants"
 	"go-auth-service/internal/domain"
 
-	"github.com/redis/go-redis/v9"
+	"github.com/google/uuid"
 )
 
 type authUsecase struct {
 	userRepo       domain.UserRepository
 	tokenManager   domain.TokenManager
 	passwordHasher domain.PasswordHasher
-	redisClient    *redis.Client
+	revocations    domain.RevocationStore
+	threatDetector domain.LoginThreatDetector
+	challenges     domain.ChallengeVerifier
+	auditLogger    domain.AuditLogger
+	eventPublisher domain.EventPublisher
+	passwordPolicy domain.PasswordPolicy
+	breachChecker  domain.BreachedPasswordChecker
+	history        domain.PasswordHistoryRepository
+	sessions       domain.SessionRepository
+	metrics        domain.Metrics
 }
 
-func NewAuthUsecase(userRepo domain.UserRepository, tokenManager domain.TokenManager, passwordHasher domain.PasswordHasher, redisClient *redis.Client) domain.AuthUsecase {
-	return &authUsecase{
+// Option configures optional collaborators of the auth usecase.
+type Option func(*authUsecase)
+
+func WithLoginThreatDetector(detector domain.LoginThreatDetector) Option {
+	return func(u *authUsecase) {
+		u.threatDetector = detector
+	}
+}
+
+// WithChallengeVerifier checks the challenge responses of login sources the
+// threat detector flagged with ThreatActionChallenge.
+func WithChallengeVerifier(verifier domain.ChallengeVerifier) Option {
+	return func(u *authUsecase) {
+		u.challenges = verifier
+	}
+}
+
+func WithAuditLogger(auditLogger domain.AuditLogger) Option {
+	return func(u *authUsecase) {
+		u.auditLogger = auditLogger
+	}
+}
+
+func WithEventPublisher(publisher domain.EventPublisher) Option {
+	return func(u *authUsecase) {
+		u.eventPublisher = publisher
+	}
+}
+
+func WithPasswordPolicy(policy domain.PasswordPolicy) Option {
+	return func(u *authUsecase) {
+		u.passwordPolicy = policy
+	}
+}
+
+// WithLoginBreachCheck checks the password of every successful login against
+// a breach corpus and flags matching accounts for a forced password change.
+func WithLoginBreachCheck(checker domain.BreachedPasswordChecker) Option {
+	return func(u *authUsecase) {
+		u.breachChecker = checker
+	}
+}
+
+// WithPasswordHistory rejects new passwords matching one of the user's recent
+// passwords and records every password the usecase sets.
+func WithPasswordHistory(history domain.PasswordHistoryRepository) Option {
+	return func(u *authUsecase) {
+		u.history = history
+	}
+}
+
+// WithSessions tracks every login as a session that operators can list and
+// revoke. Refresh tokens without a live session are rejected.
+func WithSessions(sessions domain.SessionRepository) Option {
+	return func(u *authUsecase) {
+		u.sessions = sessions
+	}
+}
+
+// WithMetrics counts flow outcomes and times password hashing and refresh
+// token validation.
+func WithMetrics(metrics domain.Metrics) Option {
+	return func(u *authUsecase) {
+		u.metrics = metrics
+	}
+}
+
+func NewAuthUsecase(userRepo domain.UserRepository, tokenManager domain.TokenManager, passwordHasher domain.PasswordHasher, revocations domain.RevocationStore, opts ...Option) domain.AuthUsecase {
+	u := &authUsecase{
 		userRepo:       userRepo,
 		tokenManager:   tokenManager,
 		passwordHasher: passwordHasher,
-		redisClient:    redisClient,
+		revocations:    revocations,
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return tracedAuthUsecase{next: u}
 }
 
 func (u *authUsecase) Register(ctx context.Context, user *domain.User) error {
-	existingUser, _ := u.userRepo.GetByEmail(ctx, user.Email)
-	if existingUser != nil {
-		return errors.New("email already exists")
+	candidate := domain.PasswordCandidate{Password: user.Password, Email: user.Email, Name: user.Name}
+	if err := u.validatePassword(ctx, candidate); err != nil {
+		u.countOutcome(domain.FlowRegister, domain.AuditOutcomeFailure, "password policy")
+		return err
 	}
 
-	hashedPassword, err := u.passwordHasher.HashPassword(user.Password)
+	existingUser, err := u.userRepo.GetByEmail(ctx, user.Email)
+	if err == nil {
+		u.audit(ctx, domain.AuditActionRegister, domain.AuditOutcomeFailure, existingUser.ID, user.Email, "email already exists")
+		return domain.ErrEmailTaken
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+
+	hashedPassword, err := u.hashPassword(ctx, user.Password)
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPassword
+	user.Role = domain.RoleUser
+
+	if err := u.userRepo.Create(ctx, user); err != nil {
+		reason := "could not create user"
+		if errors.Is(err, domain.ErrEmailTaken) {
+			reason = "email already exists"
+		}
+		u.audit(ctx, domain.AuditActionRegister, domain.AuditOutcomeFailure, 0, user.Email, reason)
+		return err
+	}
+	u.recordPasswordHistory(ctx, user.ID, hashedPassword)
 
-	return u.userRepo.Create(ctx, user)
+	u.audit(ctx, domain.AuditActionRegister, domain.AuditOutcomeSuccess, user.ID, user.Email, "")
+	return nil
 }
 
 func (u *authUsecase) Login(ctx context.Context, email, password string) (string, string, error) {
+	client := domain.ClientInfoFromContext(ctx)
+
+	if err := u.checkLoginSource(ctx, client.IP); err != nil {
+		u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeFailure, 0, email, err.Error())
+		return "", "", err
+	}
+
 	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeFailure, 0, email, "could not look up user")
+		return "", "", err
+	}
 	if err != nil {
-		return "", "", errors.New("invalid credentials")
+		u.recordLoginFailure(ctx, domain.LoginAttempt{Email: email, Password: password, IP: client.IP})
+		u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeFailure, 0, email, "unknown email")
+		return "", "", domain.ErrInvalidCredentials
 	}
 
-	if err := u.passwordHasher.CheckPassword(user.Password, password); err != nil {
-		return "", "", errors.New("invalid credentials")
+	if err := u.checkPassword(ctx, user.Password, password); err != nil {
+		u.recordLoginFailure(ctx, domain.LoginAttempt{Email: email, Password: password, IP: client.IP})
+		u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeFailure, user.ID, email, "wrong password")
+		return "", "", domain.ErrInvalidCredentials
 	}
 
-	accessToken, err := u.tokenManager.GenerateAccessToken(user)
+	if user.DisabledAt != nil {
+		u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeFailure, user.ID, email, "account disabled")
+		return "", "", domain.ErrAccountDisabled
+	}
+
+	u.upgradePasswordHash(ctx, user, password)
+	u.flagBreachedPassword(ctx, user, password)
+
+	sessionID := u.newSessionID()
+	accessToken, err := u.tokenManager.GenerateAccessToken(user, sessionID)
 	if err != nil {
+		u.countOutcome(domain.FlowLogin, domain.AuditOutcomeFailure, "could not issue tokens")
 		return "", "", err
 	}
 
-	refreshToken, err := u.tokenManager.GenerateRefreshToken(user)
+	refreshToken, err := u.tokenManager.GenerateRefreshToken(user, sessionID)
 	if err != nil {
+		u.countOutcome(domain.FlowLogin, domain.AuditOutcomeFailure, "could not issue tokens")
+		return "", "", err
+	}
+
+	if err := u.startSession(ctx, sessionID, user.ID, refreshToken); err != nil {
+		u.countOutcome(domain.FlowLogin, domain.AuditOutcomeFailure, "could not start session")
 		return "", "", err
 	}
 
+	u.audit(ctx, domain.AuditActionLogin, domain.AuditOutcomeSuccess, user.ID, email, "")
 	return accessToken, refreshToken, nil
 }
 
+// upgradePasswordHash re-hashes the password with the current algorithm and
+// parameters while the plaintext is at hand. Failures only delay the upgrade
+// to the next login.
+func (u *authUsecase) upgradePasswordHash(ctx context.Context, user *domain.User, password string) {
+	if !u.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := u.hashPassword(ctx, password)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+
+	if err := u.userRepo.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		slog.WarnContext(ctx, "failed to store rehashed password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.Password = hash
+}
+
+// flagBreachedPassword marks the account for rotation when its password shows
+// up in the breach corpus. The login itself still succeeds; clients are
+// expected to send the user to change their password.
+func (u *authUsecase) flagBreachedPassword(ctx context.Context, user *domain.User, password string) {
+	if u.breachChecker == nil || user.MustChangePassword {
+		return
+	}
+
+	breached, err := u.breachChecker.IsBreached(ctx, password)
+	if err != nil {
+		slog.WarnContext(ctx, "breached password check failed", "user_id", user.ID, "error", err)
+		return
+	}
+	if !breached {
+		return
+	}
+
+	if err := u.userRepo.SetMustChangePassword(ctx, user.ID, true); err != nil {
+		slog.WarnContext(ctx, "failed to flag breached password", "user_id", user.ID, "error", err)
+		return
+	}
+	user.MustChangePassword = true
+	u.audit(ctx, domain.AuditActionPasswordBreach, domain.AuditOutcomeFlagged, user.ID, user.Email, "password found in breach corpus")
+}
+
+// checkLoginSource rejects logins from sources flagged by the threat detector.
+// Detector errors fail open: an unavailable detector must not lock out every user.
+func (u *authUsecase) checkLoginSource(ctx context.Context, ip string) error {
+	if u.threatDetector == nil {
+		return nil
+	}
+
+	verdict, err := u.threatDetector.Check(ctx, ip)
+	if err != nil {
+		slog.WarnContext(ctx, "login threat check failed", "error", err)
+		return nil
+	}
+
+	switch verdict.Action {
+	case domain.ThreatActionBlock:
+		return domain.ErrLoginBlocked
+	case domain.ThreatActionChallenge:
+		return u.checkChallenge(ctx, ip)
+	}
+	return nil
+}
+
+// checkChallenge lets a flagged source log in once it solved the challenge.
+// Unlike detector errors, verifier errors fail closed: only flagged sources
+// are challenged, and they must not get in while the verifier is down.
+func (u *authUsecase) checkChallenge(ctx context.Context, ip string) error {
+	response := domain.ClientInfoFromContext(ctx).ChallengeResponse
+	if u.challenges == nil || response == "" {
+		return domain.ErrChallengeRequired
+	}
+
+	solved, err := u.challenges.Verify(ctx, response, ip)
+	if err != nil {
+		slog.WarnContext(ctx, "challenge verification failed", "error", err)
+		return domain.ErrChallengeRequired
+	}
+	if !solved {
+		return domain.ErrChallengeRequired
+	}
+	return nil
+}
+
+func (u *authUsecase) recordLoginFailure(ctx context.Context, attempt domain.LoginAttempt) {
+	if u.threatDetector == nil {
+		return
+	}
+
+	verdict, err := u.threatDetector.RecordFailure(ctx, attempt)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record login failure", "error", err)
+		return
+	}
+
+	if verdict.NewlyFlagged {
+		slog.WarnContext(ctx, "login source flagged", "scope", verdict.Scope, "source", verdict.Source, "action", verdict.Action, "reason", verdict.Reason)
+		u.audit(ctx, domain.AuditActionLoginThreat, domain.AuditOutcomeFlagged, 0, "",
+			string(verdict.Action)+" "+verdict.Scope+" "+verdict.Source+": "+verdict.Reason)
+		u.publish(ctx, domain.EventLoginSourceFlagged, 0, map[string]any{
+			"action": string(verdict.Action),
+			"scope":  verdict.Scope,
+			"source": verdict.Source,
+			"reason": verdict.Reason,
+		})
+	}
+}
+
 func (u *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
 	// Check if token is blacklisted
-	if u.redisClient != nil {
-		val, _ := u.redisClient.Get(ctx, constant.STR_BLACKLIST+refreshToken).Result()
-		if val != "" {
-			return "", "", errors.New("token is blacklisted")
+	if u.revocations != nil {
+		revoked, err := u.revocations.IsRevoked(ctx, refreshToken)
+		if u.metrics != nil {
+			u.metrics.RevocationCheck(domain.RevocationCheckRefresh, revoked, err)
+		}
+		if err != nil {
+			u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, 0, "", "revocation status unavailable")
+			return "", "", err
+		}
+		if revoked {
+			// A rotated or logged-out refresh token being presented again
+			// means it was most likely copied.
+			var userID uint
+			if claims, err := u.tokenManager.ValidateToken(refreshToken, true); err == nil {
+				userID = claims.UserID
+			}
+			u.audit(ctx, domain.AuditActionRefreshReuse, domain.AuditOutcomeFailure, userID, "", "blacklisted refresh token presented")
+			u.publish(ctx, domain.EventRefreshTokenReuse, userID, map[string]any{"ip": domain.ClientInfoFromContext(ctx).IP})
+			return "", "", domain.ErrTokenRevoked
 		}
 	}
 
+	start := time.Now()
 	claims, err := u.tokenManager.ValidateToken(refreshToken, true)
+	if u.metrics != nil {
+		u.metrics.ObserveTokenValidation(true, time.Since(start), err)
+	}
 	if err != nil {
+		u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, 0, "", "invalid refresh token")
 		return "", "", err
 	}
 
 	user, err := u.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
+		u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, claims.UserID, "", "user not found")
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// The token outlived its account.
+			return "", "", fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+		}
 		return "", "", err
 	}
+	if user.DisabledAt != nil {
+		u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, user.ID, user.Email, "account disabled")
+		return "", "", domain.ErrAccountDisabled
+	}
+
+	// Refresh tokens issued before sessions were tracked carry no session;
+	// they are adopted into a new one instead of being rejected.
+	sessionID := claims.SessionID
+	adopt := u.sessions != nil && sessionID == ""
+	if adopt {
+		sessionID = u.newSessionID()
+	}
 
-	newAccessToken, err := u.tokenManager.GenerateAccessToken(user)
+	newAccessToken, err := u.tokenManager.GenerateAccessToken(user, sessionID)
 	if err != nil {
+		u.countOutcome(domain.FlowRefresh, domain.AuditOutcomeFailure, "could not issue tokens")
 		return "", "", err
 	}
 
 	// Optionally rotate refresh token
-	newRefreshToken, err := u.tokenManager.GenerateRefreshToken(user)
+	newRefreshToken, err := u.tokenManager.GenerateRefreshToken(user, sessionID)
 	if err != nil {
+		u.countOutcome(domain.FlowRefresh, domain.AuditOutcomeFailure, "could not issue tokens")
+		return "", "", err
+	}
+
+	// Invalidate the old refresh token before its session moves on. If that
+	// fails the old token would stay usable next to the new one, so no tokens
+	// are issued; the session still belongs to the old token, which the
+	// client can retry with.
+	if err := u.revoke(ctx, refreshToken, claims.Expiry); err != nil {
+		u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, user.ID, user.Email, "could not revoke rotated token")
 		return "", "", err
 	}
 
-	// Invalidate old refresh token if rotation is enabled
-	if u.redisClient != nil {
-		u.redisClient.Set(ctx, constant.STR_BLACKLIST+refreshToken, "true", time.Until(claims.Expiry))
+	if adopt {
+		if err := u.startSession(ctx, sessionID, user.ID, newRefreshToken); err != nil {
+			u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, user.ID, user.Email, "could not start session")
+			return "", "", err
+		}
+	} else if u.sessions != nil {
+		newClaims, err := u.tokenManager.ValidateToken(newRefreshToken, true)
+		if err != nil {
+			u.countOutcome(domain.FlowRefresh, domain.AuditOutcomeFailure, "could not issue tokens")
+			return "", "", err
+		}
+		if err := u.sessions.Rotate(ctx, refreshToken, newRefreshToken, newClaims.Expiry); err != nil {
+			reason := "could not rotate session"
+			if errors.Is(err, domain.ErrSessionRevoked) {
+				reason = "session revoked"
+			}
+			u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeFailure, user.ID, user.Email, reason)
+			return "", "", err
+		}
 	}
 
+	u.audit(ctx, domain.AuditActionRefresh, domain.AuditOutcomeSuccess, user.ID, user.Email, "")
 	return newAccessToken, newRefreshToken, nil
 }
 
 func (u *authUsecase) Logout(ctx context.Context, accessToken string, refreshToken string) error {
-	if u.redisClient == nil {
-		return nil
-	}
-
 	// Blacklist access token
+	var userID uint
 	accessClaims, err := u.tokenManager.ValidateToken(accessToken, false)
 	if err == nil {
-		u.redisClient.Set(ctx, constant.STR_BLACKLIST+accessToken, "true", time.Until(accessClaims.Expiry))
+		userID = accessClaims.UserID
+		if err := u.revoke(ctx, accessToken, accessClaims.Expiry); err != nil {
+			u.countOutcome(domain.FlowLogout, domain.AuditOutcomeFailure, "could not revoke token")
+			return err
+		}
 	}
 
-	// Blacklist refresh token
-	refreshClaims, err := u.tokenManager.ValidateToken(refreshToken, true)
-	if err == nil {
-		u.redisClient.Set(ctx, constant.STR_BLACKLIST+refreshToken, "true", time.Until(refreshClaims.Expiry))
+	// Blacklist refresh token, which clients may omit
+	if refreshToken != "" {
+		refreshClaims, err := u.tokenManager.ValidateToken(refreshToken, true)
+		if err == nil {
+			if err := u.revoke(ctx, refreshToken, refreshClaims.Expiry); err != nil {
+				u.countOutcome(domain.FlowLogout, domain.AuditOutcomeFailure, "could not revoke token")
+				return err
+			}
+			if u.sessions != nil {
+				if err := u.sessions.DeleteByToken(ctx, refreshToken); err != nil {
+					u.countOutcome(domain.FlowLogout, domain.AuditOutcomeFailure, "could not end session")
+					return err
+				}
+			}
+		}
 	}
 
+	u.audit(ctx, domain.AuditActionLogout, domain.AuditOutcomeSuccess, userID, "", "")
 	return nil
 }
 
 func (u *authUsecase) GetMe(ctx context.Context, userID uint) (*domain.User, error) {
 	return u.userRepo.GetByID(ctx, userID)
 }
+
+func (u *authUsecase) UpdateProfile(ctx context.Context, userID uint, name string) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Name = name
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		u.audit(ctx, domain.AuditActionProfileUpdate, domain.AuditOutcomeFailure, userID, user.Email, "could not update user")
+		return nil, err
+	}
+
+	u.audit(ctx, domain.AuditActionProfileUpdate, domain.AuditOutcomeSuccess, userID, user.Email, "")
+	return user, nil
+}
+
+func (u *authUsecase) DeleteAccount(ctx context.Context, userID uint, password string) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkPassword(ctx, user.Password, password); err != nil {
+		u.audit(ctx, domain.AuditActionAccountDelete, domain.AuditOutcomeFailure, userID, user.Email, "wrong password")
+		return domain.ErrInvalidCredentials
+	}
+
+	if err := u.userRepo.Delete(ctx, userID); err != nil {
+		u.audit(ctx, domain.AuditActionAccountDelete, domain.AuditOutcomeFailure, userID, user.Email, "could not delete user")
+		return err
+	}
+
+	u.endSessions(ctx, userID)
+
+	u.audit(ctx, domain.AuditActionAccountDelete, domain.AuditOutcomeSuccess, userID, user.Email, "")
+	return nil
+}
+
+// audit records an event without affecting the outcome of the calling flow.
+// Events of the register, login, refresh and logout flows are also counted.
+func (u *authUsecase) audit(ctx context.Context, action, outcome string, userID uint, email, reason string) {
+	if flow, ok := auditFlows[action]; ok {
+		u.countOutcome(flow, outcome, reason)
+	}
+
+	if u.auditLogger == nil {
+		return
+	}
+
+	event := &domain.AuditEvent{
+		Action:  action,
+		Outcome: outcome,
+		Email:   email,
+		Reason:  reason,
+	}
+	if userID != 0 {
+		event.UserID = &userID
+	}
+
+	if err := u.auditLogger.Log(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to write audit event", "action", action, "error", err)
+	}
+}
+
+var auditFlows = map[string]string{
+	domain.AuditActionRegister:     domain.FlowRegister,
+	domain.AuditActionLogin:        domain.FlowLogin,
+	domain.AuditActionRefresh:      domain.FlowRefresh,
+	domain.AuditActionRefreshReuse: domain.FlowRefresh,
+	domain.AuditActionLogout:       domain.FlowLogout,
+}
+
+func (u *authUsecase) countOutcome(flow, outcome, reason string) {
+	if u.metrics != nil {
+		u.metrics.AuthOutcome(flow, outcome, reason)
+	}
+}
+
+func (u *authUsecase) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := startSpan(ctx, "PasswordHasher.HashPassword")
+	start := time.Now()
+	hash, err := u.passwordHasher.HashPassword(password)
+	if u.metrics != nil {
+		u.metrics.ObservePasswordHash(domain.HashOperationHash, time.Since(start))
+	}
+	endSpan(span, err)
+	return hash, err
+}
+
+// checkPassword does not mark its span failed for a wrong password.
+func (u *authUsecase) checkPassword(ctx context.Context, hash, password string) error {
+	_, span := startSpan(ctx, "PasswordHasher.CheckPassword")
+	start := time.Now()
+	err := u.passwordHasher.CheckPassword(hash, password)
+	if u.metrics != nil {
+		u.metrics.ObservePasswordHash(domain.HashOperationVerify, time.Since(start))
+	}
+	span.End()
+	return err
+}
+
+func (u *authUsecase) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkPassword(ctx, user.Password, currentPassword); err != nil {
+		u.audit(ctx, domain.AuditActionPasswordChange, domain.AuditOutcomeFailure, userID, user.Email, "wrong current password")
+		return domain.ErrInvalidCredentials
+	}
+
+	candidate := domain.PasswordCandidate{Password: newPassword, UserID: userID, Email: user.Email, Name: user.Name}
+	if err := u.validatePassword(ctx, candidate); err != nil {
+		u.audit(ctx, domain.AuditActionPasswordChange, domain.AuditOutcomeFailure, userID, user.Email, err.Error())
+		return err
+	}
+
+	if err := u.checkPasswordReuse(ctx, user, newPassword); err != nil {
+		u.audit(ctx, domain.AuditActionPasswordChange, domain.AuditOutcomeFailure, userID, user.Email, err.Error())
+		return err
+	}
+
+	hash, err := u.hashPassword(ctx, newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, userID, hash); err != nil {
+		u.audit(ctx, domain.AuditActionPasswordChange, domain.AuditOutcomeFailure, userID, user.Email, "could not update password")
+		return err
+	}
+	u.recordPasswordHistory(ctx, userID, hash)
+
+	u.audit(ctx, domain.AuditActionPasswordChange, domain.AuditOutcomeSuccess, userID, user.Email, "")
+	return nil
+}
+
+func (u *authUsecase) validatePassword(ctx context.Context, candidate domain.PasswordCandidate) error {
+	if u.passwordPolicy == nil {
+		return nil
+	}
+	return u.passwordPolicy.Validate(ctx, candidate)
+}
+
+// newSessionID returns the ID of a new session, or "" when sessions are not
+// tracked.
+func (u *authUsecase) newSessionID() string {
+	if u.sessions == nil {
+		return ""
+	}
+	return uuid.NewString()
+}
+
+// startSession records the login behind refreshToken.
+func (u *authUsecase) startSession(ctx context.Context, sessionID string, userID uint, refreshToken string) error {
+	if u.sessions == nil {
+		return nil
+	}
+
+	claims, err := u.tokenManager.ValidateToken(refreshToken, true)
+	if err != nil {
+		return err
+	}
+
+	client := domain.ClientInfoFromContext(ctx)
+	return u.sessions.Create(ctx, &domain.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		ExpiresAt: claims.Expiry,
+	}, refreshToken)
+}
+
+// endSessions runs after the account is gone, so its refresh tokens fail on
+// the user lookup anyway. A failure here is only logged, although the access
+// tokens of the sessions then stay valid until they expire.
+func (u *authUsecase) endSessions(ctx context.Context, userID uint) {
+	if u.sessions == nil {
+		return
+	}
+
+	if _, err := u.sessions.DeleteByUser(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "failed to delete sessions", "user_id", userID, "error", err)
+	}
+}
+
+func (u *authUsecase) revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	if u.revocations == nil {
+		return nil
+	}
+	return u.revocations.Revoke(ctx, token, expiresAt)
+}
+
+// checkPasswordReuse compares the new password against the current one and
+// the user's password history. Hashes are salted, so every entry has to be
+// checked with the hasher rather than compared directly.
+func (u *authUsecase) checkPasswordReuse(ctx context.Context, user *domain.User, password string) error {
+	if u.history == nil {
+		return nil
+	}
+
+	hashes, err := u.history.Recent(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range append([]string{user.Password}, hashes...) {
+		if u.checkPassword(ctx, hash, password) == nil {
+			return &domain.PasswordPolicyError{Violations: []domain.PolicyViolation{{
+				Rule:    "reused",
+				Message: "Password was used recently; choose a different one",
+			}}}
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory runs after the password is stored, so a failure here
+// only weakens reuse detection and is logged rather than returned.
+func (u *authUsecase) recordPasswordHistory(ctx context.Context, userID uint, hash string) {
+	if u.history == nil {
+		return
+	}
+
+	if err := u.history.Append(ctx, userID, hash); err != nil {
+		slog.WarnContext(ctx, "failed to record password history", "user_id", userID, "error", err)
+	}
+}
+
+// publish emits a domain event without affecting the outcome of the calling
+// flow. User lifecycle events are not published here: the user repository
+// writes them to the outbox in the same transaction as the change.
+func (u *authUsecase) publish(ctx context.Context, eventType string, userID uint, data map[string]any) {
+	if u.eventPublisher == nil {
+		return
+	}
+
+	event := domain.NewDomainEvent(eventType, userID, data)
+	if err := u.eventPublisher.Publish(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to publish event", "event_type", eventType, "error", err)
+	}
+}
@@ -39,18 +39,53 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*domain.User
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	args := m.Called(ctx, id, hash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetMustChangePassword(ctx context.Context, id uint, mustChange bool) error {
+	args := m.Called(ctx, id, mustChange)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id uint, hash string) error {
+	args := m.Called(ctx, id, hash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	args := m.Called(ctx, id, disabledAt)
+	return args.Error(0)
+}
+
 // MockTokenManager
 type MockTokenManager struct {
 	mock.Mock
 }
 
-func (m *MockTokenManager) GenerateAccessToken(user *domain.User) (string, error) {
-	args := m.Called(user)
+func (m *MockTokenManager) GenerateAccessToken(user *domain.User, sessionID string) (string, error) {
+	args := m.Called(user, sessionID)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockTokenManager) GenerateRefreshToken(user *domain.User) (string, error) {
-	args := m.Called(user)
+func (m *MockTokenManager) GenerateRefreshToken(user *domain.User, sessionID string) (string, error) {
+	args := m.Called(user, sessionID)
 	return args.String(0), args.Error(1)
 }
 
@@ -77,6 +112,175 @@ func (m *MockPasswordHasher) CheckPassword(hash, password string) error {
 	return args.Error(0)
 }
 
+func (m *MockPasswordHasher) NeedsRehash(hash string) bool {
+	args := m.Called(hash)
+	return args.Bool(0)
+}
+
+// MockLoginThreatDetector
+type MockLoginThreatDetector struct {
+	mock.Mock
+}
+
+func (m *MockLoginThreatDetector) Check(ctx context.Context, ip string) (domain.ThreatVerdict, error) {
+	args := m.Called(ctx, ip)
+	return args.Get(0).(domain.ThreatVerdict), args.Error(1)
+}
+
+func (m *MockLoginThreatDetector) RecordFailure(ctx context.Context, attempt domain.LoginAttempt) (domain.ThreatVerdict, error) {
+	args := m.Called(ctx, attempt)
+	return args.Get(0).(domain.ThreatVerdict), args.Error(1)
+}
+
+// MockChallengeVerifier
+type MockChallengeVerifier struct {
+	mock.Mock
+}
+
+func (m *MockChallengeVerifier) Verify(ctx context.Context, response, ip string) (bool, error) {
+	args := m.Called(ctx, response, ip)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAuditLogger
+type MockAuditLogger struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogger) Log(ctx context.Context, event *domain.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// MockEventPublisher
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event domain.DomainEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// MockPasswordPolicy
+type MockPasswordPolicy struct {
+	mock.Mock
+}
+
+func (m *MockPasswordPolicy) Validate(ctx context.Context, candidate domain.PasswordCandidate) error {
+	args := m.Called(ctx, candidate)
+	return args.Error(0)
+}
+
+// MockBreachedPasswordChecker
+type MockBreachedPasswordChecker struct {
+	mock.Mock
+}
+
+func (m *MockBreachedPasswordChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	args := m.Called(ctx, password)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockPasswordHistoryRepository
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordHistoryRepository) Append(ctx context.Context, userID uint, hash string) error {
+	args := m.Called(ctx, userID, hash)
+	return args.Error(0)
+}
+
+func (m *MockPasswordHistoryRepository) Recent(ctx context.Context, userID uint) ([]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockRevocationStore
+type MockRevocationStore struct {
+	mock.Mock
+}
+
+func (m *MockRevocationStore) Revoke(ctx context.Context, token string, expiresAt time.Time) error {
+	args := m.Called(ctx, token, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRevocationStore) IsRevoked(ctx context.Context, token string) (bool, error) {
+	args := m.Called(ctx, token)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockSessionRepository
+type MockMetrics struct {
+	mock.Mock
+}
+
+func (m *MockMetrics) AuthOutcome(flow, outcome, reason string) {
+	m.Called(flow, outcome, reason)
+}
+
+func (m *MockMetrics) ObservePasswordHash(operation string, d time.Duration) {
+	m.Called(operation, d)
+}
+
+func (m *MockMetrics) ObserveTokenValidation(refresh bool, d time.Duration, err error) {
+	m.Called(refresh, d, err)
+}
+
+func (m *MockMetrics) RevocationCheck(source string, revoked bool, err error) {
+	m.Called(source, revoked, err)
+}
+
+func (m *MockMetrics) DependencyError(dependency, operation string) {
+	m.Called(dependency, operation)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *domain.Session, refreshToken string) error {
+	args := m.Called(ctx, session, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Rotate(ctx context.Context, oldToken, newToken string, expiresAt time.Time) error {
+	args := m.Called(ctx, oldToken, newToken, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) ListByUser(ctx context.Context, userID uint) ([]domain.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) DeleteByToken(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) DeleteByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSessionRepository) CountActive(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestRegister(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenManager := new(MockTokenManager)
@@ -91,7 +295,7 @@ func TestRegister(t *testing.T) {
 			Name:     "Test User",
 		}
 
-		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(nil, errors.New("not found"))
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(nil, domain.ErrUserNotFound)
 		mockPasswordHasher.On("HashPassword", user.Password).Return("hashed_password", nil)
 		mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 			return u.Email == user.Email && u.Password == "hashed_password"
@@ -117,9 +321,23 @@ func TestRegister(t *testing.T) {
 		err := authUsecase.Register(context.Background(), user)
 
 		assert.Error(t, err)
-		assert.Equal(t, "email already exists", err.Error())
+		assert.ErrorIs(t, err, domain.ErrEmailTaken)
 		mockUserRepo.AssertExpectations(t)
 	})
+
+	t.Run("LookupFailure", func(t *testing.T) {
+		user := &domain.User{Email: "unreachable@example.com", Password: "password"}
+		dbErr := errors.New("connection refused")
+
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(nil, dbErr)
+
+		err := authUsecase.Register(context.Background(), user)
+
+		assert.ErrorIs(t, err, dbErr, "not reported as a taken email")
+		mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.Email == user.Email
+		}))
+	})
 }
 
 func TestLogin(t *testing.T) {
@@ -137,8 +355,9 @@ func TestLogin(t *testing.T) {
 
 		mockUserRepo.On("GetByEmail", mock.Anything, email).Return(user, nil)
 		mockPasswordHasher.On("CheckPassword", hashedPassword, password).Return(nil)
-		mockTokenManager.On("GenerateAccessToken", user).Return("access_token", nil)
-		mockTokenManager.On("GenerateRefreshToken", user).Return("refresh_token", nil)
+		mockPasswordHasher.On("NeedsRehash", hashedPassword).Return(false)
+		mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("refresh_token", nil)
 
 		accessToken, refreshToken, err := authUsecase.Login(context.Background(), email, password)
 
@@ -150,16 +369,59 @@ func TestLogin(t *testing.T) {
 		mockTokenManager.AssertExpectations(t)
 	})
 
+	t.Run("StartsSession", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockSessions := new(MockSessionRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, nil, usecase.WithSessions(mockSessions))
+		user := &domain.User{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		expiry := time.Now().Add(24 * time.Hour)
+		ctx := domain.ContextWithClientInfo(context.Background(), domain.ClientInfo{IP: "203.0.113.7", UserAgent: "test-agent"})
+
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "password").Return(nil)
+		mockPasswordHasher.On("NeedsRehash", user.Password).Return(false)
+		mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("refresh_token", nil)
+		mockTokenManager.On("ValidateToken", "refresh_token", true).Return(&domain.TokenClaims{UserID: 1, Expiry: expiry}, nil)
+		mockSessions.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Session) bool {
+			return s.UserID == 1 && s.IP == "203.0.113.7" && s.UserAgent == "test-agent" && s.ExpiresAt.Equal(expiry)
+		}), "refresh_token").Return(nil)
+
+		_, _, err := authUsecase.Login(ctx, user.Email, "password")
+
+		assert.NoError(t, err)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("DisabledAccount", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockPasswordHasher := new(MockPasswordHasher)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, nil)
+		disabledAt := time.Now()
+		user := &domain.User{ID: 1, Email: "disabled@example.com", Password: "hashed_password", DisabledAt: &disabledAt}
+
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "password").Return(nil)
+
+		_, _, err := authUsecase.Login(context.Background(), user.Email, "password")
+
+		assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+		mockTokenManager.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything)
+	})
+
 	t.Run("InvalidCredentials_UserNotFound", func(t *testing.T) {
 		email := "nonexistent@example.com"
 		password := "password"
 
-		mockUserRepo.On("GetByEmail", mock.Anything, email).Return(nil, errors.New("not found"))
+		mockUserRepo.On("GetByEmail", mock.Anything, email).Return(nil, domain.ErrUserNotFound)
 
 		_, _, err := authUsecase.Login(context.Background(), email, password)
 
 		assert.Error(t, err)
-		assert.Equal(t, "invalid credentials", err.Error())
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
 		mockUserRepo.AssertExpectations(t)
 	})
 
@@ -175,29 +437,254 @@ func TestLogin(t *testing.T) {
 		_, _, err := authUsecase.Login(context.Background(), email, password)
 
 		assert.Error(t, err)
-		assert.Equal(t, "invalid credentials", err.Error())
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
 		mockUserRepo.AssertExpectations(t)
 		mockPasswordHasher.AssertExpectations(t)
 	})
 }
 
-func TestRefreshToken(t *testing.T) {
+func TestLoginRehashesOutdatedPassword(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenManager := new(MockTokenManager)
 	mockPasswordHasher := new(MockPasswordHasher)
 
-	// Note: Redis client is nil here, so blacklist check is skipped
 	authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, nil)
 
+	user := &domain.User{ID: 1, Email: "test@example.com", Password: "$2a$10$legacy"}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockPasswordHasher.On("CheckPassword", "$2a$10$legacy", "password").Return(nil)
+	mockPasswordHasher.On("NeedsRehash", "$2a$10$legacy").Return(true)
+	mockPasswordHasher.On("HashPassword", "password").Return("$argon2id$new", nil)
+	mockUserRepo.On("UpdatePasswordHash", mock.Anything, user.ID, "$argon2id$new").Return(nil)
+	mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("access_token", nil)
+	mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("refresh_token", nil)
+
+	_, _, err := authUsecase.Login(context.Background(), user.Email, "password")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "$argon2id$new", user.Password)
+	mockUserRepo.AssertExpectations(t)
+	mockPasswordHasher.AssertExpectations(t)
+}
+
+func TestLoginFlagsBreachedPassword(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokenManager := new(MockTokenManager)
+	mockPasswordHasher := new(MockPasswordHasher)
+	mockChecker := new(MockBreachedPasswordChecker)
+
+	authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, nil, usecase.WithLoginBreachCheck(mockChecker))
+
+	user := &domain.User{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockPasswordHasher.On("CheckPassword", user.Password, "password").Return(nil)
+	mockPasswordHasher.On("NeedsRehash", user.Password).Return(false)
+	mockChecker.On("IsBreached", mock.Anything, "password").Return(true, nil)
+	mockUserRepo.On("SetMustChangePassword", mock.Anything, user.ID, true).Return(nil)
+	mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("access_token", nil)
+	mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("refresh_token", nil)
+
+	_, _, err := authUsecase.Login(context.Background(), user.Email, "password")
+
+	assert.NoError(t, err)
+	assert.True(t, user.MustChangePassword)
+	mockUserRepo.AssertExpectations(t)
+	mockChecker.AssertExpectations(t)
+}
+
+func TestLoginThreatDetection(t *testing.T) {
+	ctx := domain.ContextWithClientInfo(context.Background(), domain.ClientInfo{IP: "203.0.113.7"})
+
+	t.Run("BlockedSource", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockDetector := new(MockLoginThreatDetector)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), new(MockPasswordHasher), nil, usecase.WithLoginThreatDetector(mockDetector))
+
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{Action: domain.ThreatActionBlock}, nil)
+
+		_, _, err := authUsecase.Login(ctx, "test@example.com", "password")
+
+		assert.ErrorIs(t, err, domain.ErrLoginBlocked)
+		mockUserRepo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+		mockDetector.AssertExpectations(t)
+	})
+
+	t.Run("ChallengeRequired", func(t *testing.T) {
+		mockDetector := new(MockLoginThreatDetector)
+		mockVerifier := new(MockChallengeVerifier)
+		authUsecase := usecase.NewAuthUsecase(new(MockUserRepository), new(MockTokenManager), new(MockPasswordHasher), nil,
+			usecase.WithLoginThreatDetector(mockDetector), usecase.WithChallengeVerifier(mockVerifier))
+
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{Action: domain.ThreatActionChallenge}, nil)
+
+		_, _, err := authUsecase.Login(ctx, "test@example.com", "password")
+
+		assert.ErrorIs(t, err, domain.ErrChallengeRequired)
+		mockVerifier.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ChallengeSolved", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockDetector := new(MockLoginThreatDetector)
+		mockVerifier := new(MockChallengeVerifier)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), new(MockPasswordHasher), nil,
+			usecase.WithLoginThreatDetector(mockDetector), usecase.WithChallengeVerifier(mockVerifier))
+		solvedCtx := domain.ContextWithClientInfo(ctx, domain.ClientInfo{IP: "203.0.113.7", ChallengeResponse: "solved"})
+
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{Action: domain.ThreatActionChallenge}, nil)
+		mockVerifier.On("Verify", mock.Anything, "solved", "203.0.113.7").Return(true, nil)
+		mockUserRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, domain.ErrUserNotFound)
+		mockDetector.On("RecordFailure", mock.Anything, mock.Anything).Return(domain.ThreatVerdict{}, nil)
+
+		_, _, err := authUsecase.Login(solvedCtx, "test@example.com", "password")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "the credentials are checked once the challenge is solved")
+		mockVerifier.AssertExpectations(t)
+	})
+
+	t.Run("ChallengeFailed", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockDetector := new(MockLoginThreatDetector)
+		mockVerifier := new(MockChallengeVerifier)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), new(MockPasswordHasher), nil,
+			usecase.WithLoginThreatDetector(mockDetector), usecase.WithChallengeVerifier(mockVerifier))
+		failedCtx := domain.ContextWithClientInfo(ctx, domain.ClientInfo{IP: "203.0.113.7", ChallengeResponse: "forged"})
+
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{Action: domain.ThreatActionChallenge}, nil)
+		mockVerifier.On("Verify", mock.Anything, "forged", "203.0.113.7").Return(false, nil).Once()
+		mockVerifier.On("Verify", mock.Anything, "forged", "203.0.113.7").Return(false, errors.New("timeout")).Once()
+
+		_, _, err := authUsecase.Login(failedCtx, "test@example.com", "password")
+		assert.ErrorIs(t, err, domain.ErrChallengeRequired)
+		_, _, err = authUsecase.Login(failedCtx, "test@example.com", "password")
+		assert.ErrorIs(t, err, domain.ErrChallengeRequired, "verifier errors fail closed")
+		mockUserRepo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("FailureRecorded", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockDetector := new(MockLoginThreatDetector)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), new(MockPasswordHasher), nil, usecase.WithLoginThreatDetector(mockDetector))
+
+		attempt := domain.LoginAttempt{Email: "unknown@example.com", Password: "password", IP: "203.0.113.7"}
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{}, nil)
+		mockUserRepo.On("GetByEmail", mock.Anything, attempt.Email).Return(nil, domain.ErrUserNotFound)
+		mockDetector.On("RecordFailure", mock.Anything, attempt).Return(domain.ThreatVerdict{}, nil)
+
+		_, _, err := authUsecase.Login(ctx, attempt.Email, attempt.Password)
+
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+		mockDetector.AssertExpectations(t)
+	})
+
+	t.Run("NewlyFlaggedSourcePublished", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockDetector := new(MockLoginThreatDetector)
+		mockPublisher := new(MockEventPublisher)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), new(MockPasswordHasher), nil,
+			usecase.WithLoginThreatDetector(mockDetector), usecase.WithEventPublisher(mockPublisher))
+
+		verdict := domain.ThreatVerdict{Action: domain.ThreatActionBlock, Scope: "ip", Source: "203.0.113.7", NewlyFlagged: true}
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{}, nil)
+		mockUserRepo.On("GetByEmail", mock.Anything, "victim@example.com").Return(nil, domain.ErrUserNotFound)
+		mockDetector.On("RecordFailure", mock.Anything, mock.Anything).Return(verdict, nil)
+		mockPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(e domain.DomainEvent) bool {
+			return e.Type == domain.EventLoginSourceFlagged && e.ID != "" && e.Data["source"] == "203.0.113.7"
+		})).Return(nil)
+
+		_, _, err := authUsecase.Login(ctx, "victim@example.com", "password")
+
+		assert.Error(t, err)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("DetectorErrorFailsOpen", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockDetector := new(MockLoginThreatDetector)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, nil, usecase.WithLoginThreatDetector(mockDetector))
+
+		user := &domain.User{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		mockDetector.On("Check", mock.Anything, "203.0.113.7").Return(domain.ThreatVerdict{}, errors.New("redis down"))
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "password").Return(nil)
+		mockPasswordHasher.On("NeedsRehash", user.Password).Return(false)
+		mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("refresh_token", nil)
+
+		accessToken, _, err := authUsecase.Login(ctx, user.Email, "password")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access_token", accessToken)
+	})
+}
+
+func TestLoginAudit(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockPasswordHasher := new(MockPasswordHasher)
+	mockAuditLogger := new(MockAuditLogger)
+
+	authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithAuditLogger(mockAuditLogger))
+
+	user := &domain.User{ID: 7, Email: "test@example.com", Password: "hashed_password"}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockPasswordHasher.On("CheckPassword", user.Password, "wrong_password").Return(errors.New("password mismatch"))
+	mockAuditLogger.On("Log", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == domain.AuditActionLogin &&
+			e.Outcome == domain.AuditOutcomeFailure &&
+			e.UserID != nil && *e.UserID == user.ID &&
+			e.Reason == "wrong password"
+	})).Return(errors.New("database unavailable"))
+
+	_, _, err := authUsecase.Login(context.Background(), user.Email, "wrong_password")
+
+	// Audit failures must not change the outcome of the login itself.
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	mockAuditLogger.AssertExpectations(t)
+}
+
+func TestLoginMetrics(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockPasswordHasher := new(MockPasswordHasher)
+	mockMetrics := new(MockMetrics)
+
+	authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithMetrics(mockMetrics))
+
+	user := &domain.User{ID: 7, Email: "test@example.com", Password: "hashed_password"}
+	mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockPasswordHasher.On("CheckPassword", user.Password, "wrong_password").Return(errors.New("password mismatch"))
+	mockMetrics.On("ObservePasswordHash", domain.HashOperationVerify, mock.Anything).Return()
+	mockMetrics.On("AuthOutcome", domain.FlowLogin, domain.AuditOutcomeFailure, "wrong password").Return()
+
+	_, _, err := authUsecase.Login(context.Background(), user.Email, "wrong_password")
+
+	assert.Error(t, err)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestRefreshToken(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokenManager := new(MockTokenManager)
+	mockPasswordHasher := new(MockPasswordHasher)
+
+	mockRevocations := new(MockRevocationStore)
+
+	authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, mockPasswordHasher, mockRevocations)
+
 	t.Run("Success", func(t *testing.T) {
 		refreshToken := "valid_refresh_token"
 		claims := &domain.TokenClaims{UserID: 1, Expiry: time.Now().Add(time.Hour)}
 		user := &domain.User{ID: 1, Email: "test@example.com"}
 
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(false, nil)
 		mockTokenManager.On("ValidateToken", refreshToken, true).Return(claims, nil)
 		mockUserRepo.On("GetByID", mock.Anything, claims.UserID).Return(user, nil)
-		mockTokenManager.On("GenerateAccessToken", user).Return("new_access_token", nil)
-		mockTokenManager.On("GenerateRefreshToken", user).Return("new_refresh_token", nil)
+		mockTokenManager.On("GenerateAccessToken", user, mock.Anything).Return("new_access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, mock.Anything).Return("new_refresh_token", nil)
+		mockRevocations.On("Revoke", mock.Anything, refreshToken, claims.Expiry).Return(nil)
 
 		newAccess, newRefresh, err := authUsecase.RefreshToken(context.Background(), refreshToken)
 
@@ -206,5 +693,271 @@ func TestRefreshToken(t *testing.T) {
 		assert.Equal(t, "new_refresh_token", newRefresh)
 		mockTokenManager.AssertExpectations(t)
 		mockUserRepo.AssertExpectations(t)
+		mockRevocations.AssertExpectations(t)
+	})
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		authUsecase := usecase.NewAuthUsecase(new(MockUserRepository), mockTokenManager, new(MockPasswordHasher), mockRevocations)
+		refreshToken := "rotated_refresh_token"
+
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(true, nil)
+		mockTokenManager.On("ValidateToken", refreshToken, true).Return(&domain.TokenClaims{UserID: 1}, nil)
+
+		_, _, err := authUsecase.RefreshToken(context.Background(), refreshToken)
+
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+		mockTokenManager.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("SessionRevoked", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		mockSessions := new(MockSessionRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, new(MockPasswordHasher), mockRevocations, usecase.WithSessions(mockSessions))
+		refreshToken := "revoked_session_token"
+		claims := &domain.TokenClaims{UserID: 1, SessionID: "session-1", Expiry: time.Now().Add(time.Hour)}
+		user := &domain.User{ID: 1, Email: "test@example.com"}
+
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(false, nil)
+		mockTokenManager.On("ValidateToken", refreshToken, true).Return(claims, nil)
+		mockUserRepo.On("GetByID", mock.Anything, claims.UserID).Return(user, nil)
+		mockTokenManager.On("GenerateAccessToken", user, claims.SessionID).Return("new_access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, claims.SessionID).Return("new_refresh_token", nil)
+		mockTokenManager.On("ValidateToken", "new_refresh_token", true).Return(claims, nil)
+		mockRevocations.On("Revoke", mock.Anything, refreshToken, claims.Expiry).Return(nil)
+		mockSessions.On("Rotate", mock.Anything, refreshToken, "new_refresh_token", claims.Expiry).Return(domain.ErrSessionRevoked)
+
+		_, _, err := authUsecase.RefreshToken(context.Background(), refreshToken)
+
+		assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+	})
+
+	t.Run("RevocationFailure", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		mockSessions := new(MockSessionRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, new(MockPasswordHasher), mockRevocations, usecase.WithSessions(mockSessions))
+		refreshToken := "unrevokable_token"
+		claims := &domain.TokenClaims{UserID: 1, SessionID: "session-1", Expiry: time.Now().Add(time.Hour)}
+		user := &domain.User{ID: 1, Email: "test@example.com"}
+
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(false, nil)
+		mockTokenManager.On("ValidateToken", refreshToken, true).Return(claims, nil)
+		mockUserRepo.On("GetByID", mock.Anything, claims.UserID).Return(user, nil)
+		mockTokenManager.On("GenerateAccessToken", user, claims.SessionID).Return("new_access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, claims.SessionID).Return("new_refresh_token", nil)
+		mockTokenManager.On("ValidateToken", "new_refresh_token", true).Return(claims, nil)
+		mockRevocations.On("Revoke", mock.Anything, refreshToken, claims.Expiry).Return(errors.New("store unavailable"))
+
+		_, _, err := authUsecase.RefreshToken(context.Background(), refreshToken)
+
+		// The session still belongs to the old token, so a retry can succeed.
+		assert.Error(t, err)
+		mockSessions.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("LegacyToken", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		mockSessions := new(MockSessionRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, new(MockPasswordHasher), mockRevocations, usecase.WithSessions(mockSessions))
+		// Issued before sessions were tracked: no session claim, no row.
+		refreshToken := "pre_session_token"
+		claims := &domain.TokenClaims{UserID: 1, Expiry: time.Now().Add(time.Hour)}
+		newClaims := &domain.TokenClaims{UserID: 1, SessionID: "adopted", Expiry: time.Now().Add(24 * time.Hour)}
+		user := &domain.User{ID: 1, Email: "test@example.com"}
+
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(false, nil)
+		mockTokenManager.On("ValidateToken", refreshToken, true).Return(claims, nil)
+		mockUserRepo.On("GetByID", mock.Anything, claims.UserID).Return(user, nil)
+		mockTokenManager.On("GenerateAccessToken", user, mock.MatchedBy(func(sid string) bool { return sid != "" })).Return("new_access_token", nil)
+		mockTokenManager.On("GenerateRefreshToken", user, mock.MatchedBy(func(sid string) bool { return sid != "" })).Return("new_refresh_token", nil)
+		mockTokenManager.On("ValidateToken", "new_refresh_token", true).Return(newClaims, nil)
+		mockSessions.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Session) bool {
+			return s.ID != "" && s.UserID == user.ID && s.ExpiresAt.Equal(newClaims.Expiry)
+		}), "new_refresh_token").Return(nil)
+		mockRevocations.On("Revoke", mock.Anything, refreshToken, claims.Expiry).Return(nil)
+
+		newAccess, newRefresh, err := authUsecase.RefreshToken(context.Background(), refreshToken)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new_access_token", newAccess)
+		assert.Equal(t, "new_refresh_token", newRefresh)
+		mockSessions.AssertExpectations(t)
+		mockSessions.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRevocations.AssertExpectations(t)
+
+		// The new tokens carry the ID of the session that was created.
+		created := mockSessions.Calls[0].Arguments.Get(1).(*domain.Session)
+		mockTokenManager.AssertCalled(t, "GenerateRefreshToken", user, created.ID)
+	})
+
+	t.Run("DisabledAccount", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, mockTokenManager, new(MockPasswordHasher), mockRevocations)
+		refreshToken := "disabled_user_token"
+		disabledAt := time.Now()
+		claims := &domain.TokenClaims{UserID: 1, Expiry: time.Now().Add(time.Hour)}
+
+		mockRevocations.On("IsRevoked", mock.Anything, refreshToken).Return(false, nil)
+		mockTokenManager.On("ValidateToken", refreshToken, true).Return(claims, nil)
+		mockUserRepo.On("GetByID", mock.Anything, claims.UserID).Return(&domain.User{ID: 1, DisabledAt: &disabledAt}, nil)
+
+		_, _, err := authUsecase.RefreshToken(context.Background(), refreshToken)
+
+		assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+		mockTokenManager.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	mockTokenManager := new(MockTokenManager)
+	mockRevocations := new(MockRevocationStore)
+	authUsecase := usecase.NewAuthUsecase(new(MockUserRepository), mockTokenManager, new(MockPasswordHasher), mockRevocations)
+
+	accessExpiry := time.Now().Add(15 * time.Minute)
+	refreshExpiry := time.Now().Add(24 * time.Hour)
+	mockTokenManager.On("ValidateToken", "access_token", false).Return(&domain.TokenClaims{UserID: 1, Expiry: accessExpiry}, nil)
+	mockTokenManager.On("ValidateToken", "refresh_token", true).Return(&domain.TokenClaims{UserID: 1, Expiry: refreshExpiry}, nil)
+	mockRevocations.On("Revoke", mock.Anything, "access_token", accessExpiry).Return(nil)
+	mockRevocations.On("Revoke", mock.Anything, "refresh_token", refreshExpiry).Return(nil)
+
+	err := authUsecase.Logout(context.Background(), "access_token", "refresh_token")
+
+	assert.NoError(t, err)
+	mockRevocations.AssertExpectations(t)
+
+	t.Run("WithoutRevocationStore", func(t *testing.T) {
+		mockTokenManager := new(MockTokenManager)
+		mockSessions := new(MockSessionRepository)
+		authUsecase := usecase.NewAuthUsecase(new(MockUserRepository), mockTokenManager, new(MockPasswordHasher), nil, usecase.WithSessions(mockSessions))
+
+		mockTokenManager.On("ValidateToken", "access_token", false).Return(&domain.TokenClaims{UserID: 1, Expiry: accessExpiry}, nil)
+		mockTokenManager.On("ValidateToken", "refresh_token", true).Return(&domain.TokenClaims{UserID: 1, Expiry: refreshExpiry}, nil)
+		mockSessions.On("DeleteByToken", mock.Anything, "refresh_token").Return(nil)
+
+		err := authUsecase.Logout(context.Background(), "access_token", "refresh_token")
+
+		assert.NoError(t, err)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("WithoutRefreshToken", func(t *testing.T) {
+		mockTokenManager := new(MockTokenManager)
+		mockRevocations := new(MockRevocationStore)
+		authUsecase := usecase.NewAuthUsecase(new(MockUserRepository), mockTokenManager, new(MockPasswordHasher), mockRevocations)
+
+		mockTokenManager.On("ValidateToken", "access_token", false).Return(&domain.TokenClaims{UserID: 1, Expiry: accessExpiry}, nil)
+		mockRevocations.On("Revoke", mock.Anything, "access_token", accessExpiry).Return(nil)
+
+		err := authUsecase.Logout(context.Background(), "access_token", "")
+
+		assert.NoError(t, err)
+		mockRevocations.AssertExpectations(t)
+		mockTokenManager.AssertNotCalled(t, "ValidateToken", "", true)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "test@example.com", Name: "Test User", Password: "hashed_password"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockPolicy := new(MockPasswordPolicy)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithPasswordPolicy(mockPolicy))
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "old_password").Return(nil)
+		mockPolicy.On("Validate", mock.Anything, domain.PasswordCandidate{
+			Password: "new long passphrase", UserID: user.ID, Email: user.Email, Name: user.Name,
+		}).Return(nil)
+		mockPasswordHasher.On("HashPassword", "new long passphrase").Return("new_hash", nil)
+		mockUserRepo.On("UpdatePassword", mock.Anything, user.ID, "new_hash").Return(nil)
+
+		err := authUsecase.ChangePassword(context.Background(), user.ID, "old_password", "new long passphrase")
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+		mockPolicy.AssertExpectations(t)
+	})
+
+	t.Run("PolicyViolation", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockPolicy := new(MockPasswordPolicy)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithPasswordPolicy(mockPolicy))
+
+		policyErr := &domain.PasswordPolicyError{Violations: []domain.PolicyViolation{{Rule: "min_length"}}}
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "old_password").Return(nil)
+		mockPolicy.On("Validate", mock.Anything, mock.Anything).Return(policyErr)
+
+		err := authUsecase.ChangePassword(context.Background(), user.ID, "old_password", "a")
+
+		assert.ErrorIs(t, err, policyErr)
+		mockUserRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReusedPassword", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockHistory := new(MockPasswordHistoryRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithPasswordHistory(mockHistory))
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "old_password").Return(nil)
+		mockHistory.On("Recent", mock.Anything, user.ID).Return([]string{"older_hash"}, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "previous password").Return(errors.New("password mismatch"))
+		mockPasswordHasher.On("CheckPassword", "older_hash", "previous password").Return(nil)
+
+		err := authUsecase.ChangePassword(context.Background(), user.ID, "old_password", "previous password")
+
+		var policyErr *domain.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, "reused", policyErr.Violations[0].Rule)
+		mockUserRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RecordsHistory", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockHistory := new(MockPasswordHistoryRepository)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil, usecase.WithPasswordHistory(mockHistory))
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "old_password").Return(nil)
+		mockHistory.On("Recent", mock.Anything, user.ID).Return([]string{}, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "new long passphrase").Return(errors.New("password mismatch"))
+		mockPasswordHasher.On("HashPassword", "new long passphrase").Return("new_hash", nil)
+		mockUserRepo.On("UpdatePassword", mock.Anything, user.ID, "new_hash").Return(nil)
+		mockHistory.On("Append", mock.Anything, user.ID, "new_hash").Return(nil)
+
+		err := authUsecase.ChangePassword(context.Background(), user.ID, "old_password", "new long passphrase")
+
+		assert.NoError(t, err)
+		mockHistory.AssertExpectations(t)
+	})
+
+	t.Run("WrongCurrentPassword", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		authUsecase := usecase.NewAuthUsecase(mockUserRepo, new(MockTokenManager), mockPasswordHasher, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("CheckPassword", user.Password, "wrong").Return(errors.New("password mismatch"))
+
+		err := authUsecase.ChangePassword(context.Background(), user.ID, "wrong", "new long passphrase")
+
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
 	})
 }
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+
+	"go-auth-service/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("go-auth-service/internal/usecase")
+
+// tracedAuthUsecase starts a span for every method of the auth usecase.
+type tracedAuthUsecase struct {
+	next domain.AuthUsecase
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// endSpan marks the span failed if err is set, then ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t tracedAuthUsecase) Register(ctx context.Context, user *domain.User) error {
+	ctx, span := startSpan(ctx, "authUsecase.Register")
+	err := t.next.Register(ctx, user)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedAuthUsecase) Login(ctx context.Context, email, password string) (string, string, error) {
+	ctx, span := startSpan(ctx, "authUsecase.Login")
+	accessToken, refreshToken, err := t.next.Login(ctx, email, password)
+	endSpan(span, err)
+	return accessToken, refreshToken, err
+}
+
+func (t tracedAuthUsecase) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	ctx, span := startSpan(ctx, "authUsecase.RefreshToken")
+	newAccessToken, newRefreshToken, err := t.next.RefreshToken(ctx, refreshToken)
+	endSpan(span, err)
+	return newAccessToken, newRefreshToken, err
+}
+
+func (t tracedAuthUsecase) Logout(ctx context.Context, accessToken string, refreshToken string) error {
+	ctx, span := startSpan(ctx, "authUsecase.Logout")
+	err := t.next.Logout(ctx, accessToken, refreshToken)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedAuthUsecase) GetMe(ctx context.Context, userID uint) (*domain.User, error) {
+	ctx, span := startSpan(ctx, "authUsecase.GetMe")
+	user, err := t.next.GetMe(ctx, userID)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t tracedAuthUsecase) UpdateProfile(ctx context.Context, userID uint, name string) (*domain.User, error) {
+	ctx, span := startSpan(ctx, "authUsecase.UpdateProfile")
+	user, err := t.next.UpdateProfile(ctx, userID, name)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t tracedAuthUsecase) DeleteAccount(ctx context.Context, userID uint, password string) error {
+	ctx, span := startSpan(ctx, "authUsecase.DeleteAccount")
+	err := t.next.DeleteAccount(ctx, userID, password)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedAuthUsecase) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	ctx, span := startSpan(ctx, "authUsecase.ChangePassword")
+	err := t.next.ChangePassword(ctx, userID, currentPassword, newPassword)
+	endSpan(span, err)
+	return err
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+type userAdminUsecase struct {
+	userRepo       domain.UserRepository
+	passwordHasher domain.PasswordHasher
+	sessions       domain.SessionRepository
+	passwordPolicy domain.PasswordPolicy
+	auditLogger    domain.AuditLogger
+}
+
+// NewUserAdminUsecase applies operator actions. passwordPolicy and
+// auditLogger may be nil.
+func NewUserAdminUsecase(userRepo domain.UserRepository, passwordHasher domain.PasswordHasher, sessions domain.SessionRepository, passwordPolicy domain.PasswordPolicy, auditLogger domain.AuditLogger) domain.UserAdminUsecase {
+	return &userAdminUsecase{
+		userRepo:       userRepo,
+		passwordHasher: passwordHasher,
+		sessions:       sessions,
+		passwordPolicy: passwordPolicy,
+		auditLogger:    auditLogger,
+	}
+}
+
+func (u *userAdminUsecase) CreateUser(ctx context.Context, user *domain.User, password string) error {
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	if !domain.ValidRole(user.Role) {
+		return fmt.Errorf("unknown role %q", user.Role)
+	}
+
+	if _, err := u.userRepo.GetByEmail(ctx, user.Email); err == nil {
+		return domain.ErrEmailTaken
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+
+	candidate := domain.PasswordCandidate{Password: password, Email: user.Email, Name: user.Name}
+	if err := u.validatePassword(ctx, candidate); err != nil {
+		return err
+	}
+
+	hash, err := u.passwordHasher.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+
+	if err := u.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	u.audit(ctx, domain.AuditActionUserCreate, user.ID, user.Email, "role "+user.Role)
+	return nil
+}
+
+func (u *userAdminUsecase) SetPassword(ctx context.Context, userID uint, password string, mustChange bool) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	candidate := domain.PasswordCandidate{Password: password, UserID: userID, Email: user.Email, Name: user.Name}
+	if err := u.validatePassword(ctx, candidate); err != nil {
+		return err
+	}
+
+	hash, err := u.passwordHasher.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, userID, hash); err != nil {
+		return err
+	}
+	if mustChange {
+		if err := u.userRepo.SetMustChangePassword(ctx, userID, true); err != nil {
+			return err
+		}
+	}
+	// A reset usually follows a compromise, so surviving sessions are an
+	// error the operator has to see.
+	revoked, err := u.sessions.DeleteByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("password set, but sessions could not be revoked: %w", err)
+	}
+
+	reason := fmt.Sprintf("%d sessions revoked", revoked)
+	if mustChange {
+		reason = "temporary password, " + reason
+	}
+	u.audit(ctx, domain.AuditActionPasswordSet, userID, user.Email, reason)
+	return nil
+}
+
+func (u *userAdminUsecase) SetDisabled(ctx context.Context, userID uint, disabled bool) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !disabled {
+		if err := u.userRepo.SetDisabled(ctx, userID, nil); err != nil {
+			return err
+		}
+		u.audit(ctx, domain.AuditActionUserEnable, userID, user.Email, "")
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if err := u.userRepo.SetDisabled(ctx, userID, &now); err != nil {
+		return err
+	}
+	// Refreshing is refused for disabled accounts, but the access tokens of
+	// sessions left behind stay valid until they expire.
+	revoked, err := u.sessions.DeleteByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("account disabled, but sessions could not be revoked: %w", err)
+	}
+
+	u.audit(ctx, domain.AuditActionUserDisable, userID, user.Email, fmt.Sprintf("%d sessions revoked", revoked))
+	return nil
+}
+
+func (u *userAdminUsecase) SetRole(ctx context.Context, userID uint, role string) error {
+	if !domain.ValidRole(role) {
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return err
+	}
+	// Access tokens carry the role, so a session left behind keeps the old
+	// one until its tokens expire.
+	revoked, err := u.sessions.DeleteByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("role changed, but sessions could not be revoked: %w", err)
+	}
+
+	u.audit(ctx, domain.AuditActionRoleChange, userID, user.Email, fmt.Sprintf("%s -> %s, %d sessions revoked", user.Role, role, revoked))
+	return nil
+}
+
+func (u *userAdminUsecase) ListSessions(ctx context.Context, userID uint) ([]domain.Session, error) {
+	return u.sessions.ListByUser(ctx, userID)
+}
+
+func (u *userAdminUsecase) RevokeSession(ctx context.Context, sessionID string) error {
+	if err := u.sessions.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+
+	u.audit(ctx, domain.AuditActionSessionRevoke, 0, "", "session "+sessionID)
+	return nil
+}
+
+func (u *userAdminUsecase) RevokeSessions(ctx context.Context, userID uint) (int64, error) {
+	revoked, err := u.sessions.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	u.audit(ctx, domain.AuditActionSessionRevoke, userID, "", fmt.Sprintf("%d sessions revoked", revoked))
+	return revoked, nil
+}
+
+func (u *userAdminUsecase) validatePassword(ctx context.Context, candidate domain.PasswordCandidate) error {
+	if u.passwordPolicy == nil {
+		return nil
+	}
+	return u.passwordPolicy.Validate(ctx, candidate)
+}
+
+func (u *userAdminUsecase) audit(ctx context.Context, action string, userID uint, email, reason string) {
+	if u.auditLogger == nil {
+		return
+	}
+
+	event := &domain.AuditEvent{
+		Action:  action,
+		Outcome: domain.AuditOutcomeSuccess,
+		Email:   email,
+		Reason:  reason,
+	}
+	if userID != 0 {
+		event.UserID = &userID
+	}
+
+	if err := u.auditLogger.Log(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to write audit event", "action", action, "error", err)
+	}
+}
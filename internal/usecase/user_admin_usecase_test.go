@@ -0,0 +1,223 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-auth-service/internal/domain"
+	"go-auth-service/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserAdminCreateUser(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, mockPasswordHasher, new(MockSessionRepository), nil, nil)
+		user := &domain.User{Email: "ops@example.com"}
+
+		mockUserRepo.On("GetByEmail", mock.Anything, user.Email).Return(nil, domain.ErrUserNotFound)
+		mockPasswordHasher.On("HashPassword", "temporary password").Return("hash", nil)
+		mockUserRepo.On("Create", mock.Anything, user).Return(nil)
+
+		err := admin.CreateUser(context.Background(), user, "temporary password")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleUser, user.Role)
+		assert.Equal(t, "hash", user.Password)
+	})
+
+	t.Run("UnknownRole", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), new(MockSessionRepository), nil, nil)
+
+		err := admin.CreateUser(context.Background(), &domain.User{Email: "ops@example.com", Role: "root"}, "temporary password")
+
+		assert.Error(t, err)
+		mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmailTaken", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), new(MockSessionRepository), nil, nil)
+
+		mockUserRepo.On("GetByEmail", mock.Anything, "ops@example.com").Return(&domain.User{ID: 1}, nil)
+
+		err := admin.CreateUser(context.Background(), &domain.User{Email: "ops@example.com"}, "temporary password")
+
+		assert.ErrorIs(t, err, domain.ErrEmailTaken)
+	})
+}
+
+func TestUserAdminSetPassword(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "test@example.com"}
+
+	t.Run("RevokesSessions", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockSessions := new(MockSessionRepository)
+		mockAudit := new(MockAuditLogger)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, mockPasswordHasher, mockSessions, nil, mockAudit)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("HashPassword", "temporary password").Return("hash", nil)
+		mockUserRepo.On("UpdatePassword", mock.Anything, user.ID, "hash").Return(nil)
+		mockUserRepo.On("SetMustChangePassword", mock.Anything, user.ID, true).Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(2), nil)
+		mockAudit.On("Log", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+			return e.Action == domain.AuditActionPasswordSet && e.Reason == "temporary password, 2 sessions revoked"
+		})).Return(nil)
+
+		err := admin.SetPassword(context.Background(), user.ID, "temporary password", true)
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("SessionRevocationFailure", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockPasswordHasher := new(MockPasswordHasher)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, mockPasswordHasher, mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockPasswordHasher.On("HashPassword", "new password").Return("hash", nil)
+		mockUserRepo.On("UpdatePassword", mock.Anything, user.ID, "hash").Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(0), errors.New("database unavailable"))
+
+		err := admin.SetPassword(context.Background(), user.ID, "new password", false)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestUserAdminSetDisabled(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "test@example.com"}
+
+	t.Run("Disable", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockUserRepo.On("SetDisabled", mock.Anything, user.ID, mock.MatchedBy(func(at *time.Time) bool { return at != nil })).Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(1), nil)
+
+		err := admin.SetDisabled(context.Background(), user.ID, true)
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("SessionRevocationFailure", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockUserRepo.On("SetDisabled", mock.Anything, user.ID, mock.Anything).Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(0), errors.New("revocation store unavailable"))
+
+		err := admin.SetDisabled(context.Background(), user.ID, true)
+
+		assert.ErrorContains(t, err, "sessions could not be revoked")
+	})
+
+	t.Run("Enable", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockUserRepo.On("SetDisabled", mock.Anything, user.ID, (*time.Time)(nil)).Return(nil)
+
+		err := admin.SetDisabled(context.Background(), user.ID, false)
+
+		assert.NoError(t, err)
+		mockSessions.AssertNotCalled(t, "DeleteByUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), new(MockSessionRepository), nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, uint(9)).Return(nil, domain.ErrUserNotFound)
+
+		err := admin.SetDisabled(context.Background(), 9, true)
+
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
+
+func TestUserAdminSetRole(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "test@example.com", Role: domain.RoleAdmin}
+
+	t.Run("RevokesSessions", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockUserRepo.On("UpdateRole", mock.Anything, user.ID, domain.RoleUser).Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(2), nil)
+
+		err := admin.SetRole(context.Background(), user.ID, domain.RoleUser)
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("SessionRevocationFailure", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(mockUserRepo, new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockUserRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		mockUserRepo.On("UpdateRole", mock.Anything, user.ID, domain.RoleUser).Return(nil)
+		mockSessions.On("DeleteByUser", mock.Anything, user.ID).Return(int64(0), errors.New("revocation store unavailable"))
+
+		err := admin.SetRole(context.Background(), user.ID, domain.RoleUser)
+
+		assert.ErrorContains(t, err, "sessions could not be revoked")
+	})
+
+	t.Run("UnknownRole", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(new(MockUserRepository), new(MockPasswordHasher), mockSessions, nil, nil)
+
+		assert.Error(t, admin.SetRole(context.Background(), user.ID, "root"))
+		mockSessions.AssertNotCalled(t, "DeleteByUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserAdminSessions(t *testing.T) {
+	t.Run("RevokeSession", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(new(MockUserRepository), new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockSessions.On("Delete", mock.Anything, "session-1").Return(nil)
+
+		assert.NoError(t, admin.RevokeSession(context.Background(), "session-1"))
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("RevokeSessions", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		admin := usecase.NewUserAdminUsecase(new(MockUserRepository), new(MockPasswordHasher), mockSessions, nil, nil)
+
+		mockSessions.On("DeleteByUser", mock.Anything, uint(1)).Return(int64(3), nil)
+
+		revoked, err := admin.RevokeSessions(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, revoked)
+	})
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"time"
+
+	"go-auth-service/internal/domain"
+)
+
+const (
+	defaultDeliveryPageSize = 50
+	maxDeliveryPageSize     = 500
+)
+
+type webhookUsecase struct {
+	webhookRepo domain.WebhookRepository
+}
+
+func NewWebhookUsecase(webhookRepo domain.WebhookRepository) domain.WebhookUsecase {
+	return &webhookUsecase{webhookRepo: webhookRepo}
+}
+
+// CreateSubscription returns the subscription and its signing secret. The
+// secret is generated when not supplied and is only ever returned here.
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, rawURL string, eventTypes []string, secret string) (*domain.WebhookSubscription, string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return nil, "", domain.NewValidationError("url", "url", "must be an absolute http(s) URL")
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, "", domain.NewValidationError("event_types", "required", "at least one event type is required")
+	}
+	for _, t := range eventTypes {
+		if t != "*" && !slices.Contains(domain.EventTypes, t) {
+			return nil, "", domain.NewValidationError("event_types", "oneof", "unknown event type: "+t)
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		secret = "whsec_" + base64.RawURLEncoding.EncodeToString(buf)
+	}
+
+	sub := &domain.WebhookSubscription{
+		URL:        parsed.String(),
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+	if err := u.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, "", err
+	}
+
+	return sub, secret, nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return u.webhookRepo.ListSubscriptions(ctx)
+}
+
+func (u *webhookUsecase) DeleteSubscription(ctx context.Context, id uint) error {
+	return u.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, status string, page, pageSize int) ([]domain.WebhookDelivery, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultDeliveryPageSize
+	}
+	if pageSize > maxDeliveryPageSize {
+		pageSize = maxDeliveryPageSize
+	}
+
+	return u.webhookRepo.ListDeliveries(ctx, status, page, pageSize)
+}
+
+// RetryDelivery puts a dead-lettered delivery back in the queue with a fresh attempt budget.
+func (u *webhookUsecase) RetryDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	delivery, err := u.webhookRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Status != domain.WebhookDeliveryDead {
+		return nil, domain.ErrDeliveryNotRetryable
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().UTC()
+	if err := u.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_checkpoints;
+DROP INDEX IF EXISTS idx_audit_events_seq;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS seq;
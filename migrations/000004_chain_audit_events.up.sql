@@ -0,0 +1,17 @@
+-- Events written before chaining keep seq 0 and stay outside the chain.
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_seq ON audit_events(seq) WHERE seq > 0;
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    event_seq BIGINT NOT NULL UNIQUE,
+    event_hash VARCHAR(64) NOT NULL,
+    signature VARCHAR(128) NOT NULL
+);
+
+CREATE OR REPLACE RULE audit_checkpoints_no_update AS ON UPDATE TO audit_checkpoints DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE audit_checkpoints_no_delete AS ON DELETE TO audit_checkpoints DO INSTEAD NOTHING;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(64) NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024)
+);
+
+-- The relay only ever scans unpublished rows.
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(id) WHERE published_at IS NULL;
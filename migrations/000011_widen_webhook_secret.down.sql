@@ -0,0 +1,2 @@
+-- Fails if a stored secret, such as an encrypted one, exceeds 255 characters.
+ALTER TABLE webhook_subscriptions ALTER COLUMN secret TYPE VARCHAR(255);
@@ -0,0 +1,2 @@
+-- Encrypted secrets carry a wrapped data key and do not fit in 255 characters.
+ALTER TABLE webhook_subscriptions ALTER COLUMN secret TYPE TEXT;
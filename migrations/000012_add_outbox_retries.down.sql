@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_outbox_events_due;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS dead_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,6 @@
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_at TIMESTAMP WITH TIME ZONE;
+
+-- The relay scans due events that are neither published nor dead-lettered.
+DROP INDEX IF EXISTS idx_outbox_events_published_at;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events(next_attempt_at, id) WHERE published_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,3 @@
+-- Fails if a deleted account shares its email with a live one.
+DROP INDEX IF EXISTS idx_users_email_active;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,6 @@
+-- Only live accounts need unique emails; soft-deleted rows keep theirs.
+-- Tables built by GORM AutoMigrate carry idx_users_email instead of the
+-- constraint.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users(email) WHERE deleted_at IS NULL;